@@ -2,10 +2,14 @@ package thingmodel
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"time"
 
 	"github.com/AtomPod/thingmodel/thingmodel/actions"
+	"github.com/AtomPod/thingmodel/thingmodel/dataspec"
 	"github.com/AtomPod/thingmodel/thingmodel/events"
 	"github.com/AtomPod/thingmodel/thingmodel/property"
 )
@@ -23,6 +27,9 @@ type ThingModel struct {
 	// UpdatedAt 更新时间
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Types 命名的数据描述，可在属性、动作与事件的数据描述中通过ref引用
+	Types dataspec.Types `json:"types,omitempty"`
+
 	// Properties 属性列表
 	Properties []property.PropertyDescription `json:"properties"`
 
@@ -37,7 +44,11 @@ func (t *ThingModel) Parse(b []byte) error {
 	if err := json.Unmarshal([]byte(b), t); err != nil {
 		return err
 	}
+	return t.UpdateData()
+}
 
+// UpdateData 解析命名类型以及属性、动作与事件的数据描述，返回所有属性、动作与事件的错误
+func (t *ThingModel) UpdateData() error {
 	if t.Properties == nil {
 		t.Properties = make([]property.PropertyDescription, 0)
 	}
@@ -50,32 +61,36 @@ func (t *ThingModel) Parse(b []byte) error {
 		t.Actions = make([]actions.ActionDescription, 0)
 	}
 
+	if err := t.Types.Parse(); err != nil {
+		return err
+	}
+
+	var errs []error
 	props := t.Properties
 	for i := 0; i < len(props); i++ {
-		if err := props[i].UpdateData(); err != nil {
-			return err
-		}
-
 		if props[i].AccessMode == "" {
 			props[i].AccessMode = "wr"
 		}
+
+		if err := props[i].UpdateDataWithTypes(t.Types); err != nil {
+			errs = append(errs, fmt.Errorf("property [%s]: %w", props[i].Name, err))
+		}
 	}
 
 	events := t.Events
 	for i := 0; i < len(events); i++ {
-		if err := events[i].UpdateData(); err != nil {
-			return err
+		if err := events[i].UpdateDataWithTypes(t.Types); err != nil {
+			errs = append(errs, fmt.Errorf("event [%s]: %w", events[i].Name, err))
 		}
 	}
 
 	actions := t.Actions
-	for  i := 0; i < len(actions); i++ {
-		if err := actions[i].UpdateData(); err != nil {
-			return err
+	for i := 0; i < len(actions); i++ {
+		if err := actions[i].UpdateDataWithTypes(t.Types); err != nil {
+			errs = append(errs, fmt.Errorf("action [%s]: %w", actions[i].Name, err))
 		}
 	}
-
-	return nil
+	return errors.Join(errs...)
 }
 
 // GetProperty 获取属性,若不存在，返回nil
@@ -109,37 +124,286 @@ func (t *ThingModel) GetAction(name string) *actions.ActionDescription {
 }
 
 func (t *ThingModel) ValidateProperty(name string, v interface{}) (bool, error) {
+	r := t.CheckProperty(name, v)
+	return r.Valid(), r.Err()
+}
+
+func (t *ThingModel) ValidateActionInput(name string, v interface{}) (bool, error) {
+	r := t.CheckActionInput(name, v)
+	return r.Valid(), r.Err()
+}
+
+func (t *ThingModel) ValidateActionOutput(name string, v interface{}) (bool, error) {
+	r := t.CheckActionOutput(name, v)
+	return r.Valid(), r.Err()
+}
+
+func (t *ThingModel) ValidateEvent(name string, v interface{}) (bool, error) {
+	r := t.CheckEvent(name, v)
+	return r.Valid(), r.Err()
+}
+
+// CheckProperty 验证属性数据，返回所有错误及其路径
+func (t *ThingModel) CheckProperty(name string, v interface{}) *dataspec.ValidationResult {
 	for _, p := range t.Properties {
 		if p.Name == name {
-			return p.Validate(v)
+			return p.Check(v)
 		}
 	}
-	return false, fmt.Errorf("property not found")
+	return notFound(name, ErrPropertyNotFound)
 }
 
-func (t *ThingModel) ValidateActionInput(name string, v interface{}) (bool, error) {
+// CheckActionInput 验证动作输入数据，返回所有错误及其路径
+func (t *ThingModel) CheckActionInput(name string, v interface{}) *dataspec.ValidationResult {
 	for _, p := range t.Actions {
 		if p.Name == name {
-			return p.InputData.Validate(v)
+			return p.CheckInput(v)
 		}
 	}
-	return false, fmt.Errorf("action not found")
+	return notFound(name, ErrActionNotFound)
 }
 
-func (t *ThingModel) ValidateActionOutput(name string, v interface{}) (bool, error) {
+// CheckActionOutput 验证动作输出数据，返回所有错误及其路径
+func (t *ThingModel) CheckActionOutput(name string, v interface{}) *dataspec.ValidationResult {
 	for _, p := range t.Actions {
 		if p.Name == name {
-			return p.OutputData.Validate(v)
+			return p.CheckOutput(v)
 		}
 	}
-	return false, fmt.Errorf("action not found")
+	return notFound(name, ErrActionNotFound)
 }
 
-func (t *ThingModel) ValidateEvent(name string, v interface{}) (bool, error) {
+// CheckEvent 验证事件数据，返回所有错误及其路径
+func (t *ThingModel) CheckEvent(name string, v interface{}) *dataspec.ValidationResult {
 	for _, e := range t.Events {
 		if e.Name == name {
-			return e.Data.Validate(v)
+			return e.Check(v)
+		}
+	}
+	return notFound(name, ErrEventNotFound)
+}
+
+// ValidateProperties 验证完整的属性上报数据，详见CheckProperties
+func (t *ThingModel) ValidateProperties(v interface{}) (bool, error) {
+	r := t.CheckProperties(v)
+	return r.Valid(), r.Err()
+}
+
+// CheckProperties 验证完整的属性上报数据，v为key是属性名称的map或结构体(使用json标签作为属性名称)，
+// 每个key必须是已声明的属性，每个值必须符合属性的数据描述，所有Required的属性必须存在，返回所有错误
+func (t *ThingModel) CheckProperties(v interface{}) *dataspec.ValidationResult {
+	return t.checkProperties(v, accessAny)
+}
+
+// ValidatePropertyWrite 验证云端对属性的写入，详见CheckPropertyWrite
+func (t *ThingModel) ValidatePropertyWrite(name string, v interface{}) (bool, error) {
+	r := t.CheckPropertyWrite(name, v)
+	return r.Valid(), r.Err()
+}
+
+// CheckPropertyWrite 验证云端对属性的写入，属性必须可写(访问模式包含w)
+func (t *ThingModel) CheckPropertyWrite(name string, v interface{}) *dataspec.ValidationResult {
+	return t.checkPropertyAccess(name, v, accessWrite)
+}
+
+// ValidatePropertyReport 验证设备上报的属性，详见CheckPropertyReport
+func (t *ThingModel) ValidatePropertyReport(name string, v interface{}) (bool, error) {
+	r := t.CheckPropertyReport(name, v)
+	return r.Valid(), r.Err()
+}
+
+// CheckPropertyReport 验证设备上报的属性，属性必须可上报(访问模式包含r或n)
+func (t *ThingModel) CheckPropertyReport(name string, v interface{}) *dataspec.ValidationResult {
+	return t.checkPropertyAccess(name, v, accessReport)
+}
+
+// ValidatePropertiesWrite 验证云端对多个属性的写入，详见CheckPropertiesWrite
+func (t *ThingModel) ValidatePropertiesWrite(v interface{}) (bool, error) {
+	r := t.CheckPropertiesWrite(v)
+	return r.Valid(), r.Err()
+}
+
+// CheckPropertiesWrite 验证云端对多个属性的写入，每个属性都必须可写，由于只写入部分属性，不检查Required
+func (t *ThingModel) CheckPropertiesWrite(v interface{}) *dataspec.ValidationResult {
+	return t.checkProperties(v, accessWrite)
+}
+
+// ValidatePropertiesReport 验证设备上报的完整属性，详见CheckPropertiesReport
+func (t *ThingModel) ValidatePropertiesReport(v interface{}) (bool, error) {
+	r := t.CheckPropertiesReport(v)
+	return r.Valid(), r.Err()
+}
+
+// CheckPropertiesReport 验证设备上报的完整属性，同CheckProperties，并且每个属性都必须可上报(访问模式包含r或n)
+func (t *ThingModel) CheckPropertiesReport(v interface{}) *dataspec.ValidationResult {
+	return t.checkProperties(v, accessReport)
+}
+
+// accessDirection 属性数据的传输方向
+type accessDirection int
+
+const (
+	// accessAny 不检查访问模式
+	accessAny accessDirection = iota
+
+	// accessWrite 云端写入设备
+	accessWrite
+
+	// accessReport 设备上报云端
+	accessReport
+)
+
+func (t *ThingModel) checkPropertyAccess(name string, v interface{}, dir accessDirection) *dataspec.ValidationResult {
+	p := t.GetProperty(name)
+	if p == nil {
+		return notFound(name, ErrPropertyNotFound)
+	}
+
+	if r := checkAccess(p, dir); r != nil {
+		return r
+	}
+	return p.Check(v)
+}
+
+// checkAccess 检查属性是否允许该方向的访问，允许时返回nil
+func checkAccess(p *property.PropertyDescription, dir accessDirection) *dataspec.ValidationResult {
+	var err error
+	switch {
+	case dir == accessWrite && !p.Writable():
+		err = ErrPropertyNotWritable
+	case dir == accessReport && !p.Reportable():
+		err = ErrPropertyNotReadable
+	default:
+		return nil
+	}
+
+	r := &dataspec.ValidationResult{}
+	r.Add(&dataspec.Violation{
+		Path:       p.Name,
+		Code:       dataspec.CodeAccessDenied,
+		Constraint: p.AccessMode,
+		Message:    err.Error(),
+		Err:        err,
+	})
+	return r
+}
+
+func (t *ThingModel) checkProperties(v interface{}, dir accessDirection) *dataspec.ValidationResult {
+	r := &dataspec.ValidationResult{}
+
+	values, err := propertyValues(reflect.ValueOf(v))
+	if err != nil {
+		r.Add(&dataspec.Violation{
+			Value:      v,
+			Code:       dataspec.CodeTypeMismatch,
+			Constraint: err.Constraint(),
+			Message:    err.Error(),
+			Err:        err,
+		})
+		return r
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if t.GetProperty(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.Merge(notFound(name, ErrPropertyNotFound))
+	}
+
+	for i := range t.Properties {
+		p := &t.Properties[i]
+		value, ok := values[p.Name]
+		if !ok {
+			if p.Required && dir != accessWrite {
+				r.Add(&dataspec.Violation{
+					Path:       p.Name,
+					Code:       dataspec.CodeMissingField,
+					Constraint: p.Name,
+					Message:    "property is required",
+					Err:        &dataspec.FieldError{Field: p.Name, Err: dataspec.ErrMissingField},
+				})
+			}
+			continue
+		}
+
+		if ar := checkAccess(p, dir); ar != nil {
+			r.Merge(ar)
+			continue
+		}
+		r.Merge(p.Check(value))
+	}
+	return r
+}
+
+// ApplyDefaults 使用属性的默认值填充属性数据，缺失且有默认值的属性会被填充，存在的属性会递归填充缺失的结构体成员，
+// props为nil时创建新的map，返回填充后的数据
+func (t *ThingModel) ApplyDefaults(props map[string]interface{}) map[string]interface{} {
+	if props == nil {
+		props = make(map[string]interface{})
+	}
+
+	for _, p := range t.Properties {
+		value, ok := props[p.Name]
+		if !ok {
+			if p.Data.Default != nil {
+				props[p.Name] = p.Data.ApplyDefaults(nil)
+			}
+			continue
+		}
+
+		if value != nil {
+			props[p.Name] = p.Data.ApplyDefaults(value)
 		}
 	}
-	return false, fmt.Errorf("action not found")
+	return props
+}
+
+// propertyValues 将map或结构体转换为属性名称与值的映射，结构体中缺失的字段(见dataspec.FieldAbsent)不会被包含，与map中不存在该key一致
+func propertyValues(value reflect.Value) (map[string]interface{}, *dataspec.TypeError) {
+	kind := value.Kind()
+	if kind == reflect.Interface || kind == reflect.Pointer {
+		value = value.Elem()
+		kind = value.Kind()
+	}
+
+	values := make(map[string]interface{})
+	switch kind {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, &dataspec.TypeError{Expected: dataspec.StringType, Actual: value.Type().Key().Kind().String()}
+		}
+
+		iter := value.MapRange()
+		for iter.Next() {
+			values[iter.Key().String()] = iter.Value().Interface()
+		}
+	case reflect.Struct:
+		typ := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			field := typ.Field(i)
+			name := dataspec.FieldName(field)
+			if name == "" || dataspec.FieldAbsent(field, value.Field(i)) {
+				continue
+			}
+			values[name] = value.Field(i).Interface()
+		}
+	default:
+		return nil, &dataspec.TypeError{Expected: dataspec.StructType, Actual: kind.String()}
+	}
+	return values, nil
+}
+
+func notFound(name string, err error) *dataspec.ValidationResult {
+	r := &dataspec.ValidationResult{}
+	r.Add(&dataspec.Violation{
+		Path:    name,
+		Code:    dataspec.CodeNotFound,
+		Message: err.Error(),
+		Err:     err,
+	})
+	return r
 }
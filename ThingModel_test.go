@@ -1,9 +1,15 @@
 package thingmodel_test
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/AtomPod/thingmodel/thingmodel"
+	"github.com/AtomPod/thingmodel/thingmodel/actions"
+	"github.com/AtomPod/thingmodel/thingmodel/dataspec"
+	"github.com/AtomPod/thingmodel/thingmodel/events"
+	"github.com/AtomPod/thingmodel/thingmodel/property"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -125,3 +131,755 @@ func TestStringValid(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckProperty(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	r := thm.CheckProperty("hello", map[string]interface{}{
+		"name": "1234567890123456",
+		"age":  16,
+	})
+	assert.False(t, r.Valid())
+	assert.NotNil(t, r.Err())
+	assert.Len(t, r.Violations, 2)
+
+	paths := map[string]dataspec.ViolationCode{}
+	for _, v := range r.Violations {
+		paths[v.Path] = v.Code
+	}
+	assert.Equal(t, dataspec.CodeLengthMismatch, paths["hello.name"])
+	assert.Equal(t, dataspec.CodeOutOfRange, paths["hello.age"])
+
+	r = thm.CheckProperty("temp", []interface{}{50.0, 60.0, 70.0, 101.0, 80.005})
+	assert.Len(t, r.Violations, 2)
+	assert.Equal(t, "temp[3]", r.Violations[0].Path)
+	assert.Equal(t, dataspec.CodeOutOfRange, r.Violations[0].Code)
+	assert.Equal(t, 101.0, r.Violations[0].Value)
+	assert.Equal(t, "temp[4]", r.Violations[1].Path)
+	assert.Equal(t, dataspec.CodeStepMismatch, r.Violations[1].Code)
+
+	r = thm.CheckProperty("temp", []interface{}{50.0, 60.0, 70.0, 80.0, 90.0})
+	assert.True(t, r.Valid())
+	assert.Nil(t, r.Err())
+
+	r = thm.CheckProperty("unknown", 1)
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, dataspec.CodeNotFound, r.Violations[0].Code)
+}
+
+func TestValidateErrors(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	_, err = thm.ValidateProperty("hello", map[string]interface{}{
+		"name": 1,
+		"age":  16,
+		"sex":  "male",
+	})
+	assert.ErrorIs(t, err, dataspec.ErrTypeMismatch)
+	assert.ErrorIs(t, err, dataspec.ErrOutOfRange)
+	assert.ErrorIs(t, err, dataspec.ErrUnknownField)
+	assert.NotErrorIs(t, err, dataspec.ErrStepMismatch)
+
+	var rangeErr *dataspec.RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, int64(0), rangeErr.Min)
+	assert.Equal(t, int64(15), rangeErr.Max)
+
+	var fieldErr *dataspec.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "sex", fieldErr.Field)
+
+	_, err = thm.ValidateProperty("test_string_5", "123456")
+	var lengthErr *dataspec.LengthError
+	assert.ErrorAs(t, err, &lengthErr)
+	assert.Equal(t, 5, lengthErr.Max)
+	assert.Equal(t, 6, lengthErr.Length)
+
+	_, err = thm.ValidateProperty("unknown", 1)
+	assert.ErrorIs(t, err, thingmodel.ErrPropertyNotFound)
+
+	_, err = thm.ValidateActionInput("unknown", 1)
+	assert.ErrorIs(t, err, thingmodel.ErrActionNotFound)
+
+	_, err = thm.ValidateEvent("unknown", 1)
+	assert.ErrorIs(t, err, thingmodel.ErrEventNotFound)
+}
+
+func TestCheckProperties(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	ok, err := thm.ValidateProperties(map[string]interface{}{
+		"test_string_5": "123",
+		"hello": map[string]interface{}{
+			"name": "abc",
+			"age":  10,
+		},
+	})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	r := thm.CheckProperties(map[string]interface{}{
+		"test_string_5": "123456",
+		"unknown":       1,
+	})
+	assert.Len(t, r.Violations, 3)
+	assert.Equal(t, "unknown", r.Violations[0].Path)
+	assert.ErrorIs(t, r.Violations[0], thingmodel.ErrPropertyNotFound)
+	assert.Equal(t, "test_string_5", r.Violations[1].Path)
+	assert.ErrorIs(t, r.Violations[1], dataspec.ErrLengthMismatch)
+	assert.Equal(t, "hello", r.Violations[2].Path)
+	assert.Equal(t, dataspec.CodeMissingField, r.Violations[2].Code)
+	assert.ErrorIs(t, r.Violations[2], dataspec.ErrMissingField)
+
+	type hello struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	type report struct {
+		Str   string `json:"test_string_5,omitempty"`
+		Hello *hello `json:"hello"`
+		skip  int
+	}
+	ok, err = thm.ValidateProperties(&report{Str: "12", Hello: &hello{Name: "a", Age: 3}})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	// nil指针与omitempty的空值视为缺失，与map一致
+	type partial struct {
+		Str   string     `json:"test_string_5,omitempty"`
+		Temp  *[]float64 `json:"temp"`
+		Hello *hello     `json:"hello"`
+	}
+	r = thm.CheckProperties(partial{})
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, "hello", r.Violations[0].Path)
+	assert.Equal(t, dataspec.CodeMissingField, r.Violations[0].Code)
+
+	temp := []float64{60, 61, 62, 63, 64}
+	ok, err = thm.ValidateProperties(partial{Temp: &temp, Hello: &hello{Name: "a"}})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidateProperties(1)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, dataspec.ErrTypeMismatch)
+}
+
+func TestPropertyAccess(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`
+	{
+		"name": "sensors",
+		"properties": [
+			{
+				"name": "temperature",
+				"access_mode": "rn",
+				"data": {"type": "number", "specs": {"min": -40, "max": 120}}
+			},
+			{
+				"name": "target",
+				"access_mode": "w",
+				"data": {"type": "integer", "specs": {"min": 16, "max": 30}}
+			},
+			{
+				"name": "power",
+				"data": {"type": "boolean", "specs": {}}
+			},
+			{
+				"name": "alarm",
+				"access_mode": "n",
+				"data": {"type": "boolean", "specs": {}}
+			}
+		]
+	}
+	`))
+	assert.Nil(t, err)
+	assert.True(t, thm.GetProperty("temperature").Notifiable())
+	assert.False(t, thm.GetProperty("power").Notifiable())
+
+	ok, err := thm.ValidatePropertyWrite("temperature", 25.0)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, thingmodel.ErrPropertyNotWritable)
+
+	ok, err = thm.ValidatePropertyReport("temperature", 25.0)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	// 仅主动上报的属性同样可以上报，但不能写入
+	ok, err = thm.ValidatePropertyReport("alarm", true)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidatePropertiesReport(map[string]interface{}{"alarm": true, "temperature": 25.0})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidatePropertyWrite("alarm", true)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, thingmodel.ErrPropertyNotWritable)
+
+	ok, err = thm.ValidatePropertyReport("target", 20)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, thingmodel.ErrPropertyNotReadable)
+
+	ok, err = thm.ValidatePropertyWrite("target", 20)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidatePropertyWrite("power", true)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	r := thm.CheckPropertiesWrite(map[string]interface{}{
+		"temperature": 25.0,
+		"target":      40,
+	})
+	assert.Len(t, r.Violations, 2)
+	assert.Equal(t, dataspec.CodeAccessDenied, r.Violations[0].Code)
+	assert.ErrorIs(t, r.Violations[1], dataspec.ErrOutOfRange)
+
+	r = thm.CheckPropertiesReport(map[string]interface{}{
+		"temperature": 25.0,
+		"power":       false,
+	})
+	assert.True(t, r.Valid())
+
+	err = thm.Parse([]byte(`{"properties": [{"name": "a", "access_mode": "rr", "data": {"type": "boolean"}}]}`))
+	assert.NotNil(t, err)
+}
+
+func TestApplyDefaults(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`
+	{
+		"name": "thermostat",
+		"properties": [
+			{
+				"name": "target",
+				"data": {"type": "integer", "default": 20, "specs": {"min": 16, "max": 30}}
+			},
+			{
+				"name": "schedule",
+				"data": {
+					"type": "struct",
+					"nullable": true,
+					"specs": {
+						"enabled": {"type": "boolean", "default": false, "specs": {}},
+						"start": {"type": "string", "specs": {}},
+						"note": {"type": "string", "nullable": true, "specs": {}}
+					}
+				}
+			}
+		]
+	}
+	`))
+	assert.Nil(t, err)
+
+	props := thm.ApplyDefaults(map[string]interface{}{
+		"schedule": map[string]interface{}{
+			"start": "08:00",
+			"note":  nil,
+		},
+	})
+	assert.Equal(t, int64(20), props["target"])
+	assert.Equal(t, false, props["schedule"].(map[string]interface{})["enabled"])
+
+	ok, err := thm.ValidateProperties(props)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidateProperty("schedule", nil)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidateProperty("target", nil)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, dataspec.ErrInvalidValue)
+
+	props = thm.ApplyDefaults(map[string]interface{}{"target": 25})
+	assert.Equal(t, 25, props["target"])
+	_, ok = props["schedule"]
+	assert.False(t, ok)
+
+	err = thm.Parse([]byte(`{"properties": [{"name": "a", "data": {"type": "integer", "default": 40, "specs": {"min": 16, "max": 30}}}]}`))
+	assert.ErrorIs(t, err, dataspec.ErrOutOfRange)
+}
+
+func TestUnionActionInput(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`
+	{
+		"name": "timer",
+		"actions": [
+			{
+				"name": "set_schedule",
+				"input_data": {
+					"type": "union",
+					"specs": {
+						"discriminator": "kind",
+						"alternatives": [
+							{
+								"type": "struct",
+								"specs": {
+									"kind": {"type": "enum", "required": true, "specs": {"value_type": "string", "values": {"once": ""}}},
+									"time": {"type": "timestamp", "required": true, "specs": {"format": "unix"}}
+								}
+							},
+							{
+								"type": "struct",
+								"specs": {
+									"kind": {"type": "enum", "required": true, "specs": {"value_type": "string", "values": {"weekly": ""}}},
+									"weekday": {"type": "integer", "required": true, "specs": {"min": 0, "max": 6}}
+								}
+							}
+						]
+					}
+				},
+				"output_data": {"type": "void", "specs": {}}
+			},
+			{
+				"name": "set_value",
+				"input_data": {
+					"type": "union",
+					"specs": {
+						"alternatives": [
+							{"type": "integer", "specs": {"min": 0, "max": 10}},
+							{"type": "number", "specs": {"min": 5, "max": 20}},
+							{"type": "string", "specs": {}}
+						]
+					}
+				},
+				"output_data": {"type": "void", "specs": {}}
+			}
+		]
+	}
+	`))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Action string
+		Value  interface{}
+		Ok     bool
+	}{
+		{"set_schedule", map[string]interface{}{"kind": "once", "time": 1704178800}, true},
+		{"set_schedule", map[string]interface{}{"kind": "weekly", "weekday": 3}, true},
+		{"set_schedule", map[string]interface{}{"kind": "weekly", "time": 1704178800}, false},
+		{"set_schedule", map[string]interface{}{"kind": "daily"}, false},
+		{"set_schedule", map[string]interface{}{"weekday": 3}, false},
+		{"set_value", 2, true},
+		{"set_value", 15.5, true},
+		{"set_value", 8, false},
+		{"set_value", "auto", true},
+		{"set_value", true, false},
+	}
+
+	for _, v := range validData {
+		ok, err := thm.ValidateActionInput(v.Action, v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	_, err = thm.ValidateActionInput("set_value", 8)
+	assert.ErrorIs(t, err, dataspec.ErrUnionAmbiguous)
+
+	_, err = thm.ValidateActionInput("set_schedule", map[string]interface{}{"kind": "daily"})
+	assert.ErrorIs(t, err, dataspec.ErrUnionMismatch)
+
+	var unionErr *dataspec.UnionError
+	assert.ErrorAs(t, err, &unionErr)
+	assert.Len(t, unionErr.Failures, 2)
+	assert.ErrorIs(t, unionErr.Failures[0], dataspec.ErrNotAllowed)
+	assert.ErrorIs(t, unionErr.Failures[1], dataspec.ErrNotAllowed)
+}
+
+func TestTypes(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`
+	{
+		"name": "timer",
+		"types": {
+			"weekday": {"type": "integer", "specs": {"min": 0, "max": 6}},
+			"schedule": {
+				"type": "struct",
+				"specs": {
+					"start": {"type": "timestamp", "required": true, "specs": {"format": "unix"}},
+					"weekday": {"ref": "weekday", "required": true}
+				}
+			}
+		},
+		"properties": [
+			{
+				"name": "schedules",
+				"data": {"type": "array", "specs": {"max_length": 4, "data": {"ref": "schedule"}}}
+			},
+			{
+				"name": "next",
+				"data": {"ref": "schedule", "nullable": true}
+			}
+		],
+		"actions": [
+			{
+				"name": "add_schedule",
+				"input_data": {"ref": "schedule"},
+				"output_data": {"ref": "weekday"}
+			}
+		]
+	}
+	`))
+	assert.Nil(t, err)
+
+	schedule := map[string]interface{}{"start": 1704178800, "weekday": 3}
+	ok, err := thm.ValidateActionInput("add_schedule", schedule)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidateProperty("schedules", []interface{}{schedule, map[string]interface{}{"start": 1704178800, "weekday": 7}})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, dataspec.ErrOutOfRange)
+
+	ok, err = thm.ValidateProperty("next", nil)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = thm.ValidateActionOutput("add_schedule", 6)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	errorModels := []string{
+		`{"properties": [{"name": "a", "data": {"ref": "unknown"}}]}`,
+		`{"types": {"a": {"ref": "b"}, "b": {"ref": "a"}}}`,
+		`{"types": {"node": {"type": "struct", "specs": {"next": {"ref": "node"}}}}}`,
+		`{"types": {"a": {"type": "integer", "specs": {}}}, "properties": [{"name": "a", "data": {"ref": "a", "specs": {"min": 1}}}]}`,
+	}
+
+	for _, m := range errorModels {
+		thm := &thingmodel.ThingModel{}
+		assert.NotNil(t, thm.Parse([]byte(m)), m)
+	}
+
+	thm = &thingmodel.ThingModel{}
+	err = thm.Parse([]byte(`{"types": {"a": {"type": "struct", "specs": {"b": {"ref": "b"}}}, "b": {"type": "array", "specs": {"max_length": 1, "data": {"ref": "a"}}}}}`))
+	assert.ErrorContains(t, err, "circular ref")
+}
+
+func TestStructAssertions(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`
+	{
+		"name": "thermostat",
+		"actions": [
+			{
+				"name": "set_schedule",
+				"input_data": {
+					"type": "struct",
+					"specs": {
+						"assertions": [
+							"start_time < end_time",
+							"min_temp <= max_temp && max_temp - min_temp >= 2",
+							"mode != 'eco' || max_temp <= 26"
+						],
+						"fields": {
+							"start_time": {"type": "timestamp", "required": true, "specs": {}},
+							"end_time": {"type": "timestamp", "required": true, "specs": {}},
+							"min_temp": {"type": "number", "specs": {"min": 5, "max": 35}},
+							"max_temp": {"type": "number", "specs": {"min": 5, "max": 35}},
+							"mode": {"type": "enum", "specs": {"value_type": "string", "values": {"eco": "", "comfort": ""}}}
+						}
+					}
+				},
+				"output_data": {"type": "void", "specs": {}}
+			}
+		]
+	}
+	`))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value map[string]interface{}
+		Ok    bool
+	}{
+		{map[string]interface{}{"start_time": "2024-01-01T08:00:00+08:00", "end_time": "2024-01-01T01:00:00Z", "min_temp": 18, "max_temp": 24}, true},
+		{map[string]interface{}{"start_time": "2024-01-01T08:00:00+08:00", "end_time": "2024-01-01T00:00:00Z", "min_temp": 18, "max_temp": 24}, false},
+		{map[string]interface{}{"start_time": "2024-01-01T08:00:00Z", "end_time": "2024-01-01T09:00:00Z", "min_temp": 24, "max_temp": 18}, false},
+		{map[string]interface{}{"start_time": "2024-01-01T08:00:00Z", "end_time": "2024-01-01T09:00:00Z", "min_temp": 18, "max_temp": 19}, false},
+		{map[string]interface{}{"start_time": "2024-01-01T08:00:00Z", "end_time": "2024-01-01T09:00:00Z", "mode": "eco", "min_temp": 18, "max_temp": 28}, false},
+		{map[string]interface{}{"start_time": "2024-01-01T08:00:00Z", "end_time": "2024-01-01T09:00:00Z", "mode": "comfort", "min_temp": 18, "max_temp": 28}, true},
+		{map[string]interface{}{"start_time": "2024-01-01T08:00:00Z", "end_time": "2024-01-01T09:00:00Z"}, true},
+	}
+
+	for _, v := range validData {
+		ok, err := thm.ValidateActionInput("set_schedule", v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	r := thm.CheckActionInput("set_schedule", map[string]interface{}{
+		"start_time": "2024-01-01T08:00:00Z", "end_time": "2024-01-01T09:00:00Z", "min_temp": 24, "max_temp": 18,
+	})
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, dataspec.CodeAssertionFailed, r.Violations[0].Code)
+	assert.Equal(t, "min_temp <= max_temp && max_temp - min_temp >= 2", r.Violations[0].Constraint)
+	assert.ErrorIs(t, r, dataspec.ErrAssertionFailed)
+
+	errorAssertions := []string{
+		`"start_time < 5"`,
+		`"unknown > 1"`,
+		`"min_temp + 1"`,
+		`"(min_temp < max_temp"`,
+		`"min_temp < 'a'"`,
+	}
+
+	for _, a := range errorAssertions {
+		thm := &thingmodel.ThingModel{}
+		err := thm.Parse([]byte(`{"actions": [{"name": "a", "output_data": {"type": "void"}, "input_data": {"type": "struct", "specs": {
+			"assertions": [` + a + `],
+			"fields": {
+				"start_time": {"type": "timestamp", "specs": {}},
+				"min_temp": {"type": "number", "specs": {}},
+				"max_temp": {"type": "number", "specs": {}}
+			}
+		}}}]}`))
+		assert.NotNil(t, err, a)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`
+	{
+		"id": "thermostat-v1",
+		"name": "thermostat",
+		"types": {
+			"range": {
+				"type": "struct",
+				"specs": {
+					"assertions": ["low <= high"],
+					"fields": {
+						"low": {"type": "number", "required": true, "specs": {"min": 5, "max": 35, "step": 0.5}},
+						"high": {"type": "number", "required": true, "specs": {"min": 5, "max": 35, "step": 0.5}}
+					}
+				}
+			}
+		},
+		"properties": [
+			{
+				"name": "target",
+				"access_mode": "rw",
+				"data": {"type": "number", "default": 22, "specs": {"min": 5, "max": 35, "step": 0.5, "unit": "°C"}}
+			},
+			{
+				"name": "comfort",
+				"data": {"ref": "range", "nullable": true}
+			},
+			{
+				"name": "counter",
+				"access_mode": "rn",
+				"data": {"type": "integer", "specs": {"format": "uint64"}}
+			},
+			{
+				"name": "price",
+				"data": {"type": "decimal", "specs": {"min": "0", "scale": 2}}
+			},
+			{
+				"name": "zones",
+				"data": {"type": "map", "specs": {"max_size": 8, "value": {"type": "enum", "specs": {"value_type": "string", "values": {"on": "", "off": ""}}}}}
+			}
+		],
+		"actions": [
+			{
+				"name": "set_comfort",
+				"input_data": {"ref": "range"},
+				"output_data": {"type": "void"}
+			}
+		],
+		"events": [
+			{
+				"name": "fault",
+				"type": "alert",
+				"data": {"type": "struct", "specs": {"code": {"type": "integer", "required": true, "specs": {"min": 0}}, "message": {"type": "string", "specs": {"length": 64}}}}
+			}
+		]
+	}
+	`))
+	assert.Nil(t, err)
+
+	// 在Go中修改规格
+	target := thm.Properties[0].Data.Specs.(*dataspec.NumericDataSpec)
+	target.Max = 30
+
+	fault := thm.Events[0].Data.Specs.(*dataspec.StructDataSpec)
+	fault.Fields["level"] = &dataspec.DataDescription{Type: dataspec.IntegerType, Specs: &dataspec.IntegerDataSpec{Min: 0, Max: 3}}
+	fault.FieldOrder = append(fault.FieldOrder, "level")
+
+	b, err := json.Marshal(thm)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), "1.7976931348623157e+308")
+	assert.NotContains(t, string(b), "9223372036854775807")
+
+	parsed := &thingmodel.ThingModel{}
+	assert.Nil(t, parsed.Parse(b))
+
+	again, err := json.Marshal(parsed)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(b), string(again))
+	assert.Equal(t, string(b), string(again))
+
+	ok, _ := parsed.ValidateProperty("target", 32)
+	assert.False(t, ok)
+
+	ok, _ = parsed.ValidateEvent("fault", map[string]interface{}{"code": 1, "level": 4})
+	assert.False(t, ok)
+
+	ok, _ = parsed.ValidateActionInput("set_comfort", map[string]interface{}{"low": 25, "high": 20})
+	assert.False(t, ok)
+
+	ok, _ = parsed.ValidateProperty("counter", uint64(1)<<63)
+	assert.True(t, ok)
+
+	s := string(again)
+	assert.Less(t, strings.Index(s, `"code"`), strings.Index(s, `"level"`))
+	assert.Contains(t, s, `{"ref":"range","nullable":true}`)
+}
+
+func TestBuilder(t *testing.T) {
+	thm, err := thingmodel.NewBuilder("light", "智能灯").
+		Type("color", dataspec.Struct().
+			Field("r", dataspec.Integer().IntRange(0, 255).Required()).
+			Field("g", dataspec.Integer().IntRange(0, 255).Required()).
+			Field("b", dataspec.Integer().IntRange(0, 255).Required())).
+		Property(
+			property.NewProperty("power", dataspec.Boolean("开", "关")).Required(),
+			property.NewProperty("color", dataspec.Ref("color").Nullable()).AccessMode("rwn"),
+		).
+		Action(actions.NewAction("blink", dataspec.Struct().
+			Field("times", dataspec.Integer().IntRange(1, 10).Required()).
+			Field("colors", dataspec.Array(dataspec.Ref("color")).Length(1, 3)), dataspec.Void())).
+		Event(events.NewEvent("overheat", events.Alert, dataspec.Number().NumberRange(-40, 150).Unit("°C"))).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "wr", thm.GetProperty("power").AccessMode)
+	assert.True(t, thm.GetProperty("color").Notifiable())
+
+	ok, _ := thm.ValidateProperty("color", map[string]interface{}{"r": 1, "g": 2, "b": 3})
+	assert.True(t, ok)
+
+	ok, _ = thm.ValidateProperty("color", nil)
+	assert.True(t, ok)
+
+	ok, _ = thm.ValidateActionInput("blink", map[string]interface{}{"times": 2, "colors": []interface{}{map[string]interface{}{"r": 1, "g": 2, "b": 256}}})
+	assert.False(t, ok)
+
+	ok, _ = thm.ValidateEvent("overheat", 151)
+	assert.False(t, ok)
+
+	// 构造的物模型与json解析的物模型一致
+	b, err := json.Marshal(thm)
+	assert.Nil(t, err)
+	parsed := &thingmodel.ThingModel{}
+	assert.Nil(t, parsed.Parse(b))
+	again, err := json.Marshal(parsed)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), string(again))
+
+	// 所有构造错误一起返回
+	_, err = thingmodel.NewBuilder("light", "智能灯").
+		Type("color", dataspec.String().IntRange(0, 1)).
+		Type("color", dataspec.String()).
+		Property(
+			property.NewProperty("power", dataspec.Boolean("开", "关").Length(0, 1)),
+			property.NewProperty("power", dataspec.Boolean("开", "关")),
+		).
+		Action(actions.NewAction("blink", dataspec.Integer().Pattern("a").Scale(1), dataspec.Struct().Key(dataspec.String()))).
+		Event(events.NewEvent("overheat", events.Alert, dataspec.Number().Scale(2))).
+		Build()
+	assert.NotNil(t, err)
+	for _, msg := range []string{
+		"type [color]: DataBuilder: IntRange is not supported by type [string]",
+		"type [color]: Builder: type is duplicated",
+		"property [power]: data: DataBuilder: Length is not supported by type [boolean]",
+		"property [power]: Builder: name is duplicated",
+		"action [blink]: input: DataBuilder: Pattern is not supported by type [integer]",
+		"action [blink]: input: DataBuilder: Scale is not supported by type [integer]",
+		"action [blink]: output: DataBuilder: Key is not supported by type [struct]",
+		"event [overheat]: data: DataBuilder: Scale is not supported by type [number]",
+	} {
+		assert.ErrorContains(t, err, msg)
+	}
+
+	// 引用命名类型时不能再修改规格
+	_, err = thingmodel.NewBuilder("light", "智能灯").
+		Type("t", dataspec.Integer().IntRange(0, 100)).
+		Property(property.NewProperty("p", dataspec.Ref("t").IntRange(0, 5))).
+		Build()
+	assert.ErrorContains(t, err, "property [p]: data: DataBuilder: IntRange is not supported by ref [t]")
+
+	// 规格解析错误同样一起返回
+	_, err = thingmodel.NewBuilder("light", "智能灯").
+		Property(property.NewProperty("a", dataspec.Integer().IntRange(10, 1))).
+		Event(events.NewEvent("b", events.Info, dataspec.Ref("missing"))).
+		Build()
+	assert.ErrorContains(t, err, "property [a]")
+	assert.ErrorContains(t, err, "event [b]")
+}
+
+func TestVoidData(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`{"actions": [{"name": "reboot", "input_data": {"type": "void"}, "output_data": {"type": "void", "specs": {}}}]}`))
+	assert.Nil(t, err)
+
+	ok, err := thm.ValidateActionInput("reboot", nil)
+	assert.True(t, ok, err)
+
+	ok, err = thm.ValidateActionOutput("reboot", nil)
+	assert.True(t, ok, err)
+
+	built, err := thingmodel.NewBuilder("device", "设备").
+		Action(actions.NewAction("reboot", dataspec.Void(), dataspec.Void())).
+		Build()
+	assert.Nil(t, err)
+
+	ok, err = built.ValidateActionInput("reboot", nil)
+	assert.True(t, ok, err)
+
+	ok, err = built.ValidateActionOutput("reboot", nil)
+	assert.True(t, ok, err)
+}
+
+func TestDefaultPrecision(t *testing.T) {
+	thm := &thingmodel.ThingModel{}
+	err := thm.Parse([]byte(`{"properties": [
+		{"name": "id", "data": {"type": "integer", "default": 9007199254740993, "specs": {}}},
+		{"name": "ratio", "data": {"type": "number", "default": 0.5, "specs": {"min": 0, "max": 1}}},
+		{"name": "price", "data": {"type": "decimal", "default": 0.1, "specs": {"scale": 2}}},
+		{"name": "limits", "data": {"type": "array", "default": [9007199254740993], "specs": {"max_length": 1, "data": {"type": "integer", "specs": {}}}}}
+	]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9007199254740993), thm.GetProperty("id").Data.Default)
+	assert.Equal(t, 0.5, thm.GetProperty("ratio").Data.Default)
+	assert.Equal(t, []interface{}{int64(9007199254740993)}, thm.GetProperty("limits").Data.Default)
+
+	b, err := json.Marshal(thm)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"default":9007199254740993`)
+	assert.Contains(t, string(b), `"default":[9007199254740993]`)
+
+	parsed := &thingmodel.ThingModel{}
+	assert.Nil(t, parsed.Parse(b))
+	again, err := json.Marshal(parsed)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), string(again))
+}
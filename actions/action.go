@@ -22,6 +22,11 @@ type ActionDescription struct {
 }
 
 func (a *ActionDescription) UpdateData() error {
+	return a.UpdateDataWithTypes(nil)
+}
+
+// UpdateDataWithTypes 同UpdateData，输入与输出数据描述中的ref从types中解析
+func (a *ActionDescription) UpdateDataWithTypes(types dataspec.Types) error {
 	if len(a.Name) == 0 {
 		return fmt.Errorf("ActionDescription: name could not be empty")
 	}
@@ -30,11 +35,11 @@ func (a *ActionDescription) UpdateData() error {
 		return fmt.Errorf("ActionDescription: data field could not be empty")
 	}
 
-	if err := a.InputData.Parse(); err != nil {
+	if err := a.InputData.ParseWithTypes(types); err != nil {
 		return err
 	}
 
-	if err := a.OutputData.Parse(); err != nil {
+	if err := a.OutputData.ParseWithTypes(types); err != nil {
 		return err
 	}
 	return nil
@@ -49,3 +54,13 @@ func (a *ActionDescription) ValidateInput(v interface{}) (bool, error) {
 func (a *ActionDescription) ValidateOutput(v interface{}) (bool, error) {
 	return a.OutputData.Validate(v)
 }
+
+// CheckInput 验证输入的数据，返回所有错误
+func (a *ActionDescription) CheckInput(v interface{}) *dataspec.ValidationResult {
+	return a.InputData.Check(v)
+}
+
+// CheckOutput 验证输出的数据，返回所有错误
+func (a *ActionDescription) CheckOutput(v interface{}) *dataspec.ValidationResult {
+	return a.OutputData.Check(v)
+}
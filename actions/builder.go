@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"errors"
+
+	"github.com/AtomPod/thingmodel/thingmodel/dataspec"
+)
+
+// ActionBuilder 动作描述构造器
+type ActionBuilder struct {
+	desc   ActionDescription
+	input  *dataspec.DataBuilder
+	output *dataspec.DataBuilder
+}
+
+// NewAction 创建动作描述构造器，input与output为输入与输出的数据描述，没有输入或输出时使用dataspec.Void()
+func NewAction(name string, input, output *dataspec.DataBuilder) *ActionBuilder {
+	return &ActionBuilder{
+		desc:   ActionDescription{Name: name},
+		input:  input,
+		output: output,
+	}
+}
+
+// Name 动作名称
+func (b *ActionBuilder) Name() string {
+	return b.desc.Name
+}
+
+// Describe 动作描述，作为解释说明
+func (b *ActionBuilder) Describe(text string) *ActionBuilder {
+	b.desc.Description = text
+	return b
+}
+
+// Description 返回未解析的动作描述，以及构造过程中的所有错误
+func (b *ActionBuilder) Description() (*ActionDescription, error) {
+	desc := b.desc
+	var errs []error
+	if b.input != nil {
+		data, err := b.input.Description()
+		errs = append(errs, dataspec.PrefixErrors("input", err))
+		desc.InputData = data
+	}
+
+	if b.output != nil {
+		data, err := b.output.Description()
+		errs = append(errs, dataspec.PrefixErrors("output", err))
+		desc.OutputData = data
+	}
+	return &desc, errors.Join(errs...)
+}
+
+// Build 返回解析后的动作描述
+func (b *ActionBuilder) Build() (*ActionDescription, error) {
+	desc, err := b.Description()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := desc.UpdateData(); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
@@ -0,0 +1,167 @@
+package thingmodel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/AtomPod/thingmodel/thingmodel/actions"
+	"github.com/AtomPod/thingmodel/thingmodel/dataspec"
+	"github.com/AtomPod/thingmodel/thingmodel/events"
+	"github.com/AtomPod/thingmodel/thingmodel/property"
+)
+
+// Builder 物模型构造器，用于在Go中构造物模型，不需要编写json
+//
+// 构造过程中的错误不会中断构造，Build时返回所有属性、动作、事件与命名类型的错误
+//
+// 使用方式:
+//
+//	thm, err := thingmodel.NewBuilder("light", "智能灯").
+//		Type("color", dataspec.Struct().
+//			Field("r", dataspec.Integer().IntRange(0, 255).Required()).
+//			Field("g", dataspec.Integer().IntRange(0, 255).Required()).
+//			Field("b", dataspec.Integer().IntRange(0, 255).Required())).
+//		Property(property.NewProperty("color", dataspec.Ref("color")).AccessMode("rwn")).
+//		Event(events.NewEvent("overheat", events.Alert, dataspec.Number().Unit("°C"))).
+//		Build()
+type Builder struct {
+	id    string
+	name  string
+	types map[string]*dataspec.DataBuilder
+
+	properties []*property.PropertyBuilder
+	actions    []*actions.ActionBuilder
+	events     []*events.EventBuilder
+
+	errs []error
+}
+
+// NewBuilder 创建物模型构造器
+func NewBuilder(id, name string) *Builder {
+	return &Builder{
+		id:    id,
+		name:  name,
+		types: map[string]*dataspec.DataBuilder{},
+	}
+}
+
+// Type 添加命名类型，可在数据描述中通过dataspec.Ref引用
+func (b *Builder) Type(name string, data *dataspec.DataBuilder) *Builder {
+	if _, ok := b.types[name]; ok {
+		b.errs = append(b.errs, fmt.Errorf("type [%s]: Builder: type is duplicated", name))
+		return b
+	}
+	b.types[name] = data
+	return b
+}
+
+// Property 添加属性
+func (b *Builder) Property(ps ...*property.PropertyBuilder) *Builder {
+	b.properties = append(b.properties, ps...)
+	return b
+}
+
+// Action 添加动作
+func (b *Builder) Action(as ...*actions.ActionBuilder) *Builder {
+	b.actions = append(b.actions, as...)
+	return b
+}
+
+// Event 添加事件
+func (b *Builder) Event(es ...*events.EventBuilder) *Builder {
+	b.events = append(b.events, es...)
+	return b
+}
+
+// Build 返回解析后的物模型，存在错误时返回所有错误
+func (b *Builder) Build() (*ThingModel, error) {
+	errs := append([]error{}, b.errs...)
+	t := &ThingModel{
+		ID:         b.id,
+		Name:       b.name,
+		Properties: make([]property.PropertyDescription, 0, len(b.properties)),
+		Actions:    make([]actions.ActionDescription, 0, len(b.actions)),
+		Events:     make([]events.EventDescription, 0, len(b.events)),
+	}
+
+	if len(b.types) > 0 {
+		t.Types = make(dataspec.Types, len(b.types))
+	}
+	names := make([]string, 0, len(b.types))
+	for name := range b.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data := b.types[name]
+		if data == nil {
+			errs = append(errs, fmt.Errorf("type [%s]: Builder: data could not be nil", name))
+			continue
+		}
+
+		d, err := data.Description()
+		if err != nil {
+			errs = append(errs, dataspec.PrefixErrors(fmt.Sprintf("type [%s]", name), err))
+		}
+		t.Types[name] = d
+	}
+
+	seen := map[string]bool{}
+	for _, p := range b.properties {
+		if !b.unique(&errs, seen, "property", p.Name()) {
+			continue
+		}
+
+		d, err := p.Description()
+		if err != nil {
+			errs = append(errs, dataspec.PrefixErrors(fmt.Sprintf("property [%s]", p.Name()), err))
+		}
+		t.Properties = append(t.Properties, *d)
+	}
+
+	seen = map[string]bool{}
+	for _, a := range b.actions {
+		if !b.unique(&errs, seen, "action", a.Name()) {
+			continue
+		}
+
+		d, err := a.Description()
+		if err != nil {
+			errs = append(errs, dataspec.PrefixErrors(fmt.Sprintf("action [%s]", a.Name()), err))
+		}
+		t.Actions = append(t.Actions, *d)
+	}
+
+	seen = map[string]bool{}
+	for _, e := range b.events {
+		if !b.unique(&errs, seen, "event", e.Name()) {
+			continue
+		}
+
+		d, err := e.Description()
+		if err != nil {
+			errs = append(errs, dataspec.PrefixErrors(fmt.Sprintf("event [%s]", e.Name()), err))
+		}
+		t.Events = append(t.Events, *d)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if err := t.UpdateData(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// unique 检查名称是否重复，重复时记录错误并返回false
+func (b *Builder) unique(errs *[]error, seen map[string]bool, kind, name string) bool {
+	if seen[name] {
+		*errs = append(*errs, fmt.Errorf("%s [%s]: Builder: name is duplicated", kind, name))
+		return false
+	}
+	seen[name] = true
+	return true
+}
@@ -1,11 +1,13 @@
 package dataspec
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 )
 
-// ArrayDataSpec 数组数据，为了限制，同一数组数据类型应该相同
+// ArrayDataSpec 数组数据，为了限制，同一数组数据类型应该相同，length与min_length、max_length不能同时使用
 //
 // 使用方式:
 //
@@ -27,32 +29,157 @@ import (
 //			}
 //		}
 //	}
+//
+//	构造一个 0到10个定时任务的数组，定时任务的id不能重复
+//	{
+//		"name": "timers",
+//		"description": "定时任务",
+//		"data": {
+//			"type": "array",
+//			"specs": {
+//				"max_length": 10,
+//				"unique_key": "id",
+//				"data": {
+//					"type": "struct",
+//					"specs": {
+//						"id": {
+//							"type": "integer",
+//							"specs": {}
+//						},
+//						"time": {
+//							"type": "string",
+//							"specs": {}
+//						}
+//					}
+//				}
+//			}
+//		}
+//	}
 type ArrayDataSpec struct {
-	// Length 长度
-	Length int32 `json:"length"`
+	// Length 固定长度，数组长度必须等于该值，为零时使用MinLength与MaxLength
+	Length int32 `json:"length,omitempty"`
+
+	// MinLength 最小长度
+	MinLength int32 `json:"min_length,omitempty"`
+
+	// MaxLength 最大长度，为零时不限制，Length、MinLength与MaxLength至少需要设置一个，例如只设置min_length代表至少min_length个元素
+	MaxLength int32 `json:"max_length,omitempty"`
+
+	// Unique 数组元素是否不能重复
+	Unique bool `json:"unique,omitempty"`
+
+	// UniqueKey 结构体数组中，不能重复的成员名称，设置后按该成员判断是否重复，而不是整个元素
+	UniqueKey string `json:"unique_key,omitempty"`
 
 	// Data 数组数据类型
 	Data *DataDescription `json:"data"`
 }
 
+func (a *ArrayDataSpec) parse(res *typeResolver) error {
+	if a.Length < 0 || a.MinLength < 0 || a.MaxLength < 0 {
+		return fmt.Errorf("ArrayDataSpecs: array length could not be negative")
+	}
+
+	if a.Length != 0 && (a.MinLength != 0 || a.MaxLength != 0) {
+		return fmt.Errorf("ArrayDataSpecs: length could not be used with min_length or max_length")
+	}
+
+	if a.Length == 0 && a.MinLength == 0 && a.MaxLength == 0 {
+		return fmt.Errorf("ArrayDataSpecs: one of length, min_length or max_length must be set")
+	}
+
+	if a.MaxLength != 0 && a.MinLength > a.MaxLength {
+		return fmt.Errorf("ArrayDataSpecs: min_length could not be greater than max_length")
+	}
+
+	if a.Data == nil {
+		return fmt.Errorf("ArrayDataSpecs: data field could not be empty")
+	}
+
+	if err := a.Data.parse(res); err != nil {
+		return err
+	}
+
+	if a.UniqueKey != "" {
+		specs, ok := a.Data.Specs.(*StructDataSpec)
+		if !ok {
+			return fmt.Errorf("ArrayDataSpecs: unique_key could only be used with struct data")
+		}
+
+		if _, ok := specs.Fields[a.UniqueKey]; !ok {
+			return fmt.Errorf("ArrayDataSpecs: unique_key [%s] is not a field of struct", a.UniqueKey)
+		}
+	}
+	return nil
+}
+
+// lengthRange 数组允许的长度范围
+func (a *ArrayDataSpec) lengthRange() (int, int) {
+	if a.Length != 0 {
+		return int(a.Length), int(a.Length)
+	}
+
+	max := int(a.MaxLength)
+	if max == 0 {
+		max = math.MaxInt32
+	}
+	return int(a.MinLength), max
+}
+
 func (a *ArrayDataSpec) Validate(v interface{}) (bool, error) {
-	value := reflect.ValueOf(v)
-	typ := value.Type()
-	kind := typ.Kind()
+	r := &ValidationResult{}
+	a.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+func (a *ArrayDataSpec) check(r *ValidationResult, path string, value reflect.Value) {
+	kind := value.Kind()
 	if kind != reflect.Array && kind != reflect.Slice {
-		return false, fmt.Errorf("ArrayDataSpecs: value type is not supported")
+		r.addf(path, reflectInterface(value), newTypeError(ArrayType, value), "ArrayDataSpecs: value type is not supported")
+		return
 	}
 
 	len := value.Len()
-	if a.Length != int32(len) {
-		return false, fmt.Errorf("ArrayDataSpecs: array size too large or too small")
+	if min, max := a.lengthRange(); len < min || len > max {
+		r.addf(path, reflectInterface(value), &LengthError{Min: min, Max: max, Length: len},
+			"ArrayDataSpecs: array size must be range [%d, %d]", min, max)
+		return
 	}
 
 	for i := 0; i < len; i++ {
 		elemVal := value.Index(i)
-		if ok, err := validateReflectData(a.Data, elemVal); !ok {
-			return ok, err
+		validateReflectData(a.Data, elemVal, indexPath(path, i), r)
+	}
+
+	if a.Unique || a.UniqueKey != "" {
+		a.checkUnique(r, path, value)
+	}
+}
+
+// checkUnique 检查数组元素是否重复，元素使用json序列化后的结果进行比较，因此1与1.0被认为是重复的
+func (a *ArrayDataSpec) checkUnique(r *ValidationResult, path string, value reflect.Value) {
+	seen := make(map[string]int, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		elemVal := value.Index(i)
+		if a.UniqueKey != "" {
+			member, ok := structMember(elemVal, a.UniqueKey)
+			if !ok {
+				continue
+			}
+			elemVal = member
+		}
+
+		b, err := json.Marshal(reflectInterface(elemVal))
+		if err != nil {
+			continue
+		}
+
+		key := string(b)
+		if j, ok := seen[key]; ok {
+			r.addf(indexPath(path, i), reflectInterface(elemVal), &UniqueError{Key: a.UniqueKey, Index: j},
+				"ArrayDataSpecs: item is duplicated with [%d]", j)
+			continue
 		}
+		seen[key] = i
 	}
-	return true, nil
 }
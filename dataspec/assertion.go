@@ -0,0 +1,527 @@
+package dataspec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// 结构体断言表达式，用于描述成员之间的约束，例如 start_time < end_time、min_temp <= max_temp
+//
+// 支持的语法:
+//
+//	成员名称        start_time、max_temp，只能引用当前结构体中的数值、字符串、布尔、枚举与时间成员
+//	字面量          1、-0.5、'auto'、"auto"、true、false、null
+//	算术运算        + - * /，仅用于数值
+//	比较运算        == != < <= > >=，两侧类型必须相同，null只能用于 == 与 !=
+//	逻辑运算        ! && ||
+//	括号            (a + b) <= 100
+//
+// 断言引用的成员不存在或为null时(== 与 != 除外)，断言不适用，不会产生错误
+
+// exprKind 表达式的静态类型，在解析时检查
+type exprKind int
+
+const (
+	kindNull exprKind = iota
+	kindNumber
+	kindString
+	kindBool
+	kindTime
+)
+
+func (k exprKind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindString:
+		return "string"
+	case kindBool:
+		return "boolean"
+	case kindTime:
+		return "timestamp"
+	}
+	return "null"
+}
+
+// unknown 表达式的值无法确定，例如引用的成员不存在
+type unknown struct{}
+
+// exprNode 表达式节点，值为decimal.Decimal、string、bool、time.Time、nil或unknown
+type exprNode interface {
+	kind() exprKind
+	eval(v reflect.Value) interface{}
+}
+
+// assertion 解析后的断言
+type assertion struct {
+	expr string
+	node exprNode
+}
+
+// parseAssertion 解析断言表达式，fields用于检查成员是否存在以及成员的类型
+func parseAssertion(expr string, fields map[string]*DataDescription) (*assertion, error) {
+	tokens, err := tokenizeExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens, fields: fields}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected [%s]", p.tokens[p.pos].text)
+	}
+
+	if node.kind() != kindBool {
+		return nil, fmt.Errorf("result must be boolean, got [%s]", node.kind())
+	}
+	return &assertion{expr: expr, node: node}, nil
+}
+
+// holds 断言是否成立，值无法确定时认为成立
+func (a *assertion) holds(v reflect.Value) bool {
+	b, ok := a.node.eval(v).(bool)
+	return !ok || b
+}
+
+type tokenType int
+
+const (
+	tokenOp tokenType = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+)
+
+type exprToken struct {
+	typ  tokenType
+	text string
+}
+
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+func tokenizeExpr(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, exprToken{typ: tokenIdent, text: string(runes[start:i])})
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{typ: tokenNumber, text: string(runes[start:i])})
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != c {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, exprToken{typ: tokenString, text: string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			op := ""
+			for _, o := range exprOperators {
+				if strings.HasPrefix(string(runes[i:]), o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character [%c]", c)
+			}
+			tokens = append(tokens, exprToken{typ: tokenOp, text: op})
+			i += len([]rune(op))
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser 递归下降解析器，优先级从低到高为 || && 比较 加减 乘除 一元运算
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	fields map[string]*DataDescription
+}
+
+// accept 当前token为指定运算符之一时前进并返回该运算符
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].typ != tokenOp {
+		return "", false
+	}
+
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	x, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if op, ok := p.accept("==", "!=", "<=", ">=", "<", ">"); ok {
+		y, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return newBinaryNode(op, x, y)
+	}
+	return x, nil
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+// parseBinary 解析左结合的二元运算
+func (p *exprParser) parseBinary(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	x, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return x, nil
+		}
+
+		y, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		if x, err = newBinaryNode(op, x, y); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	op, ok := p.accept("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	if (op == "!" && x.kind() != kindBool) || (op == "-" && x.kind() != kindNumber) {
+		return nil, fmt.Errorf("operator [%s] could not be used with [%s]", op, x.kind())
+	}
+	return &unaryNode{op: op, x: x}, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.typ {
+	case tokenNumber:
+		d, err := decimal.NewFromString(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number [%s]", t.text)
+		}
+		return &literalNode{value: d, k: kindNumber}, nil
+	case tokenString:
+		return &literalNode{value: t.text, k: kindString}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return &literalNode{value: t.text == "true", k: kindBool}, nil
+		case "null":
+			return &literalNode{k: kindNull}, nil
+		}
+		return p.member(t.text)
+	}
+
+	if t.text == "(" {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing [)]")
+		}
+		return x, nil
+	}
+	return nil, fmt.Errorf("unexpected [%s]", t.text)
+}
+
+// member 创建成员节点，成员的类型由成员的数据规格决定
+func (p *exprParser) member(name string) (exprNode, error) {
+	field, ok := p.fields[name]
+	if !ok {
+		return nil, fmt.Errorf("field [%s] is not declared", name)
+	}
+
+	node := &memberNode{name: name, specs: field.Specs}
+	switch specs := field.Specs.(type) {
+	case *IntegerDataSpec, *NumericDataSpec, *DecimalDataSpec:
+		node.k = kindNumber
+	case *StringDataSpec:
+		node.k = kindString
+	case *BooleanDataSpec:
+		node.k = kindBool
+	case *TimestampDataSpec:
+		node.k = kindTime
+	case *EnumDataSpec:
+		node.k = kindNumber
+		if specs.ValueType == StringType {
+			node.k = kindString
+		}
+	default:
+		return nil, fmt.Errorf("field [%s] of type [%s] could not be used", name, field.Type)
+	}
+	return node, nil
+}
+
+type literalNode struct {
+	value interface{}
+	k     exprKind
+}
+
+func (n *literalNode) kind() exprKind {
+	return n.k
+}
+
+func (n *literalNode) eval(v reflect.Value) interface{} {
+	return n.value
+}
+
+type memberNode struct {
+	name  string
+	specs DataSpec
+	k     exprKind
+}
+
+func (n *memberNode) kind() exprKind {
+	return n.k
+}
+
+func (n *memberNode) eval(v reflect.Value) interface{} {
+	member, ok := structMember(v, n.name)
+	for ok && (member.Kind() == reflect.Interface || member.Kind() == reflect.Pointer) {
+		member = member.Elem()
+	}
+
+	if !ok || !member.IsValid() {
+		return nil
+	}
+
+	switch n.k {
+	case kindNumber:
+		if d, ok := reflectDecimal(member); ok {
+			return d
+		} else if d, ok := coerceDecimal(member, CoerceStrict); ok {
+			return d
+		}
+	case kindString:
+		if member.Kind() == reflect.String {
+			return member.String()
+		}
+	case kindBool:
+		if member.Kind() == reflect.Bool {
+			return member.Bool()
+		}
+	case kindTime:
+		if t, ok := n.specs.(*TimestampDataSpec).reflectTime(member); ok {
+			return t
+		}
+	}
+	return unknown{}
+}
+
+type unaryNode struct {
+	op string
+	x  exprNode
+}
+
+func (n *unaryNode) kind() exprKind {
+	return n.x.kind()
+}
+
+func (n *unaryNode) eval(v reflect.Value) interface{} {
+	switch x := n.x.eval(v).(type) {
+	case bool:
+		return !x
+	case decimal.Decimal:
+		return x.Neg()
+	}
+	return unknown{}
+}
+
+type binaryNode struct {
+	op   string
+	x, y exprNode
+	k    exprKind
+}
+
+// newBinaryNode 创建二元运算节点，检查两侧的类型
+func newBinaryNode(op string, x, y exprNode) (exprNode, error) {
+	xk, yk := x.kind(), y.kind()
+	mismatch := fmt.Errorf("operator [%s] could not be used with [%s] and [%s]", op, xk, yk)
+
+	n := &binaryNode{op: op, x: x, y: y, k: kindBool}
+	switch op {
+	case "&&", "||":
+		if xk != kindBool || yk != kindBool {
+			return nil, mismatch
+		}
+	case "==", "!=":
+		if xk != yk && xk != kindNull && yk != kindNull {
+			return nil, mismatch
+		}
+	case "<", "<=", ">", ">=":
+		if xk != yk || (xk != kindNumber && xk != kindString && xk != kindTime) {
+			return nil, mismatch
+		}
+	default:
+		if xk != kindNumber || yk != kindNumber {
+			return nil, mismatch
+		}
+		n.k = kindNumber
+	}
+	return n, nil
+}
+
+func (n *binaryNode) kind() exprKind {
+	return n.k
+}
+
+func (n *binaryNode) eval(v reflect.Value) interface{} {
+	x := n.x.eval(v)
+
+	// 逻辑运算短路，一侧可以确定结果时忽略另一侧
+	switch n.op {
+	case "&&":
+		if x == false {
+			return false
+		}
+		return logical(x, n.y.eval(v), true)
+	case "||":
+		if x == true {
+			return true
+		}
+		return logical(x, n.y.eval(v), false)
+	}
+
+	y := n.y.eval(v)
+	if _, ok := x.(unknown); ok {
+		return unknown{}
+	}
+	if _, ok := y.(unknown); ok {
+		return unknown{}
+	}
+
+	switch n.op {
+	case "==", "!=":
+		if x == nil || y == nil {
+			return (x == nil && y == nil) == (n.op == "==")
+		}
+		return (compareValues(x, y) == 0) == (n.op == "==")
+	}
+
+	if x == nil || y == nil {
+		return unknown{}
+	}
+
+	switch n.op {
+	case "<":
+		return compareValues(x, y) < 0
+	case "<=":
+		return compareValues(x, y) <= 0
+	case ">":
+		return compareValues(x, y) > 0
+	case ">=":
+		return compareValues(x, y) >= 0
+	}
+
+	a, b := x.(decimal.Decimal), y.(decimal.Decimal)
+	switch n.op {
+	case "+":
+		return a.Add(b)
+	case "-":
+		return a.Sub(b)
+	case "*":
+		return a.Mul(b)
+	default:
+		if b.IsZero() {
+			return unknown{}
+		}
+		return a.Div(b)
+	}
+}
+
+// logical 计算 && 与 || 的结果，x的值为identity时(&& 为true，|| 为false)结果由y决定
+func logical(x, y interface{}, identity bool) interface{} {
+	if y == !identity {
+		return !identity
+	}
+
+	if x == identity && y == identity {
+		return identity
+	}
+	return unknown{}
+}
+
+// compareValues 比较两个相同类型的值，返回-1、0或1
+func compareValues(x, y interface{}) int {
+	switch x := x.(type) {
+	case decimal.Decimal:
+		return x.Cmp(y.(decimal.Decimal))
+	case string:
+		return strings.Compare(x, y.(string))
+	case time.Time:
+		return x.Compare(y.(time.Time))
+	case bool:
+		if x == y.(bool) {
+			return 0
+		}
+		return 1
+	}
+	return 1
+}
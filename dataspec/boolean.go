@@ -1,6 +1,6 @@
 package dataspec
 
-import "fmt"
+import "reflect"
 
 // BooleanDataSpec 布尔数据类型
 //
@@ -20,17 +20,20 @@ import "fmt"
 // }
 type BooleanDataSpec struct {
 	// TrueDesc 为真时的描述
-	TrueDesc string `json:"true_desc"`
+	TrueDesc string `json:"true_desc,omitempty"`
 
 	// FalseDesc 为假时的描述
-	FalseDesc string `json:"false_desc"`
+	FalseDesc string `json:"false_desc,omitempty"`
 }
 
 func (n *BooleanDataSpec) Validate(v interface{}) (bool, error) {
-	_, ok := v.(bool)
-	if !ok {
-		return false, fmt.Errorf("BooleanDataSpecs: value type is not supported")
-	}
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
 
-	return true, nil
+func (n *BooleanDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	if v.Kind() != reflect.Bool {
+		r.addf(path, reflectInterface(v), newTypeError(BooleanType, v), "BooleanDataSpecs: value type is not supported")
+	}
 }
@@ -0,0 +1,587 @@
+package dataspec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DataBuilder 数据描述构造器，用于在Go中构造数据描述，不需要编写json
+//
+// 构造过程中的错误(例如对integer设置pattern、重复的成员)不会中断构造，而是在Description或Build时一起返回
+//
+// 使用方式:
+//
+//	data, err := dataspec.Struct().
+//		Field("name", dataspec.String().Length(1, 15).Required()).
+//		Field("age", dataspec.Integer().IntRange(0, 150).Unit("y")).
+//		Assert("age >= 18 || name == 'guest'").
+//		Build()
+type DataBuilder struct {
+	typ         DataType
+	ref         string
+	specs       DataSpec
+	required    bool
+	nullable    bool
+	def         interface{}
+	constraints []string
+
+	// fieldNames 结构体成员的声明顺序
+	fieldNames []string
+	fields     map[string]*DataBuilder
+
+	item         *DataBuilder
+	key          *DataBuilder
+	value        *DataBuilder
+	alternatives []*DataBuilder
+
+	errs []error
+}
+
+// NewDataBuilder 创建指定类型的数据描述构造器，规格使用类型的默认值，支持通过RegisterType注册的类型
+func NewDataBuilder(typ DataType) *DataBuilder {
+	b := &DataBuilder{typ: typ}
+	if factory, ok := LookupType(typ); ok {
+		b.specs = factory()
+	} else {
+		b.errorf("type [%s] is not supported", typ)
+	}
+	return b
+}
+
+// String 字符串数据
+func String() *DataBuilder {
+	return NewDataBuilder(StringType)
+}
+
+// Integer 整数数据
+func Integer() *DataBuilder {
+	return NewDataBuilder(IntegerType)
+}
+
+// Number 浮点数数据
+func Number() *DataBuilder {
+	return NewDataBuilder(NumberType)
+}
+
+// Decimal 十进制数数据
+func Decimal() *DataBuilder {
+	return NewDataBuilder(DecimalType)
+}
+
+// Boolean 布尔数据，trueDesc与falseDesc为真与假时的描述
+func Boolean(trueDesc, falseDesc string) *DataBuilder {
+	return NewDataBuilder(BooleanType).configure(func(specs DataSpec) bool {
+		s, ok := specs.(*BooleanDataSpec)
+		if ok {
+			s.TrueDesc, s.FalseDesc = trueDesc, falseDesc
+		}
+		return ok
+	}, "Boolean")
+}
+
+// Enum 整数枚举数据，values的key为枚举值，value为描述
+func Enum(values map[int64]string) *DataBuilder {
+	m := make(map[string]string, len(values))
+	for k, v := range values {
+		m[fmt.Sprint(k)] = v
+	}
+	return newEnum(IntegerType, m)
+}
+
+// StringEnum 字符串枚举数据，values的key为枚举值，value为描述
+func StringEnum(values map[string]string) *DataBuilder {
+	return newEnum(StringType, values)
+}
+
+func newEnum(valueType DataType, values map[string]string) *DataBuilder {
+	return NewDataBuilder(EnumType).configure(func(specs DataSpec) bool {
+		s, ok := specs.(*EnumDataSpec)
+		if ok {
+			s.ValueType, s.Values = valueType, values
+		}
+		return ok
+	}, "Enum")
+}
+
+// Timestamp 时间数据，format为时间的表示格式
+func Timestamp(format TimestampFormat) *DataBuilder {
+	return NewDataBuilder(TimestampType).configure(func(specs DataSpec) bool {
+		s, ok := specs.(*TimestampDataSpec)
+		if ok {
+			s.Format = format
+		}
+		return ok
+	}, "Timestamp")
+}
+
+// Bytes 二进制数据
+func Bytes() *DataBuilder {
+	return NewDataBuilder(BytesType)
+}
+
+// Array 数组数据，item为元素的数据描述，需要通过Length设置长度范围
+func Array(item *DataBuilder) *DataBuilder {
+	b := NewDataBuilder(ArrayType)
+	b.item = item
+	return b
+}
+
+// Struct 结构体数据，通过Field添加成员，成员按添加顺序声明
+func Struct() *DataBuilder {
+	b := NewDataBuilder(StructType)
+	b.fields = map[string]*DataBuilder{}
+	return b
+}
+
+// Map 字典数据，value为值的数据描述，key默认为字符串，可通过Key设置
+func Map(value *DataBuilder) *DataBuilder {
+	b := NewDataBuilder(MapType)
+	b.value = value
+	return b
+}
+
+// Union 联合数据，alternatives为候选的数据描述
+func Union(alternatives ...*DataBuilder) *DataBuilder {
+	b := NewDataBuilder(UnionType)
+	b.alternatives = alternatives
+	return b
+}
+
+// Void 空数据
+func Void() *DataBuilder {
+	return NewDataBuilder(VoidType)
+}
+
+// Ref 引用命名类型，见Types，规格由命名类型决定，因此只支持Required、Nullable、Default与Constraints
+func Ref(name string) *DataBuilder {
+	b := &DataBuilder{ref: name}
+	if name == "" {
+		b.errorf("ref could not be empty")
+	}
+	return b
+}
+
+// Required 作为结构体成员时，该成员必须存在
+func (b *DataBuilder) Required() *DataBuilder {
+	b.required = true
+	return b
+}
+
+// Nullable 值可以为null
+func (b *DataBuilder) Nullable() *DataBuilder {
+	b.nullable = true
+	return b
+}
+
+// Default 默认值
+func (b *DataBuilder) Default(v interface{}) *DataBuilder {
+	b.def = v
+	return b
+}
+
+// Constraints 添加自定义约束，约束需要通过RegisterConstraint注册
+func (b *DataBuilder) Constraints(names ...string) *DataBuilder {
+	b.constraints = append(b.constraints, names...)
+	return b
+}
+
+// IntRange 整数的取值范围
+func (b *DataBuilder) IntRange(min, max int64) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*IntegerDataSpec)
+		if ok {
+			s.Min, s.Max, s.maxDeclared = min, max, true
+		}
+		return ok
+	}, "IntRange")
+}
+
+// NumberRange 浮点数的取值范围
+func (b *DataBuilder) NumberRange(min, max float64) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*NumericDataSpec)
+		if ok {
+			s.Min, s.Max = min, max
+		}
+		return ok
+	}, "NumberRange")
+}
+
+// DecimalRange 十进制数的取值范围，min与max为十进制数字符串，为空时不限制
+func (b *DataBuilder) DecimalRange(min, max string) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*DecimalDataSpec)
+		if ok {
+			s.Min, s.Max = b.decimal("DecimalRange", min), b.decimal("DecimalRange", max)
+		}
+		return ok
+	}, "DecimalRange")
+}
+
+// TimeRange 时间的取值范围，为零值时不限制
+func (b *DataBuilder) TimeRange(min, max time.Time) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*TimestampDataSpec)
+		if ok {
+			s.Min, s.Max = nil, nil
+			if !min.IsZero() {
+				s.Min = &min
+			}
+			if !max.IsZero() {
+				s.Max = &max
+			}
+		}
+		return ok
+	}, "TimeRange")
+}
+
+// Step 步进，支持integer、number与decimal，integer的步进必须为整数
+func (b *DataBuilder) Step(step float64) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		switch s := specs.(type) {
+		case *IntegerDataSpec:
+			if step != math.Trunc(step) {
+				b.errorf("Step [%v] must be an integer", step)
+			}
+			s.Step = int64(step)
+		case *NumericDataSpec:
+			s.Step = step
+		case *DecimalDataSpec:
+			d := decimal.NewFromFloat(step)
+			s.Step = &d
+		default:
+			return false
+		}
+		return true
+	}, "Step")
+}
+
+// Unit 单位，支持integer、number与decimal
+func (b *DataBuilder) Unit(unit string) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		switch s := specs.(type) {
+		case *IntegerDataSpec:
+			s.Unit = unit
+		case *NumericDataSpec:
+			s.Unit = unit
+		case *DecimalDataSpec:
+			s.Unit = unit
+		default:
+			return false
+		}
+		return true
+	}, "Unit")
+}
+
+// Format 格式，string为StringFormat，integer为IntegerFormat，timestamp为TimestampFormat
+func (b *DataBuilder) Format(format string) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		switch s := specs.(type) {
+		case *StringDataSpec:
+			s.Format = StringFormat(format)
+		case *IntegerDataSpec:
+			s.Format = IntegerFormat(format)
+		case *TimestampDataSpec:
+			s.Format = TimestampFormat(format)
+		default:
+			return false
+		}
+		return true
+	}, "Format")
+}
+
+// Length 长度范围，支持string、bytes、array的长度与map的大小，max为零时不限制(array的min与max不能同时为零)
+func (b *DataBuilder) Length(min, max int32) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		switch s := specs.(type) {
+		case *StringDataSpec:
+			s.MinLength, s.Length = min, max
+		case *BytesDataSpec:
+			s.MinLength, s.MaxLength = min, max
+		case *ArrayDataSpec:
+			s.MinLength, s.MaxLength = min, max
+		case *MapDataSpec:
+			s.MinSize, s.MaxSize = min, max
+		default:
+			return false
+		}
+		return true
+	}, "Length")
+}
+
+// RuneLength 字符串长度按UTF-8字符计算
+func (b *DataBuilder) RuneLength() *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*StringDataSpec)
+		if ok {
+			s.LengthUnit = LengthUnitRune
+		}
+		return ok
+	}, "RuneLength")
+}
+
+// Pattern 字符串必须匹配的正则表达式
+func (b *DataBuilder) Pattern(pattern string) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*StringDataSpec)
+		if ok {
+			s.Pattern = pattern
+		}
+		return ok
+	}, "Pattern")
+}
+
+// Scale 十进制数的最大小数位数
+func (b *DataBuilder) Scale(scale int32) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*DecimalDataSpec)
+		if ok {
+			s.Scale = &scale
+		}
+		return ok
+	}, "Scale")
+}
+
+// Encoding 二进制数据在json中的编码
+func (b *DataBuilder) Encoding(encoding BytesEncoding) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*BytesDataSpec)
+		if ok {
+			s.Encoding = encoding
+		}
+		return ok
+	}, "Encoding")
+}
+
+// Unique 数组元素必须唯一，key不为空时按结构体成员判断
+func (b *DataBuilder) Unique(key string) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*ArrayDataSpec)
+		if ok {
+			s.Unique, s.UniqueKey = key == "", key
+		}
+		return ok
+	}, "Unique")
+}
+
+// Field 添加结构体成员，成员按添加顺序声明
+func (b *DataBuilder) Field(name string, data *DataBuilder) *DataBuilder {
+	if _, ok := b.specs.(*StructDataSpec); !ok {
+		return b.unsupported("Field")
+	}
+
+	if _, ok := b.fields[name]; ok {
+		b.errorf("field [%s] is duplicated", name)
+		return b
+	}
+
+	b.fieldNames = append(b.fieldNames, name)
+	b.fields[name] = data
+	return b
+}
+
+// AdditionalFields 结构体中未声明字段的处理策略
+func (b *DataBuilder) AdditionalFields(policy AdditionalFieldsPolicy) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*StructDataSpec)
+		if ok {
+			s.AdditionalFields = policy
+		}
+		return ok
+	}, "AdditionalFields")
+}
+
+// Assert 添加结构体成员之间的断言，例如 start_time < end_time
+func (b *DataBuilder) Assert(assertions ...string) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*StructDataSpec)
+		if ok {
+			s.Assertions = append(s.Assertions, assertions...)
+		}
+		return ok
+	}, "Assert")
+}
+
+// Key 字典key的数据描述，支持string、integer与enum
+func (b *DataBuilder) Key(key *DataBuilder) *DataBuilder {
+	if _, ok := b.specs.(*MapDataSpec); !ok {
+		return b.unsupported("Key")
+	}
+	b.key = key
+	return b
+}
+
+// Discriminator 联合数据的区分成员
+func (b *DataBuilder) Discriminator(name string) *DataBuilder {
+	return b.configure(func(specs DataSpec) bool {
+		s, ok := specs.(*UnionDataSpec)
+		if ok {
+			s.Discriminator = name
+		}
+		return ok
+	}, "Discriminator")
+}
+
+// Configure 直接修改数据规格，用于构造器没有提供的设置或自定义类型，例如
+//
+//	dataspec.Number().Configure(func(specs dataspec.DataSpec) {
+//		specs.(*dataspec.NumericDataSpec).AllowNaN = true
+//	})
+func (b *DataBuilder) Configure(fn func(specs DataSpec)) *DataBuilder {
+	if b.ref != "" {
+		return b.unsupported("Configure")
+	}
+
+	if b.specs != nil {
+		fn(b.specs)
+	}
+	return b
+}
+
+// Description 返回未解析的数据描述，以及构造过程中的所有错误，用于作为其它描述的一部分再统一解析
+func (b *DataBuilder) Description() (*DataDescription, error) {
+	d, errs := b.description("")
+	return d, errors.Join(errs...)
+}
+
+// Build 返回解析后的数据描述，构造过程中存在错误时返回所有错误
+func (b *DataBuilder) Build() (*DataDescription, error) {
+	d, err := b.Description()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Parse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// description 构造数据描述，子数据描述的错误以path作为前缀
+func (b *DataBuilder) description(path string) (*DataDescription, []error) {
+	errs := make([]error, 0, len(b.errs))
+	for _, err := range b.errs {
+		errs = append(errs, prefixError(path, err))
+	}
+
+	d := &DataDescription{
+		Type:        b.typ,
+		Ref:         b.ref,
+		Required:    b.required,
+		Nullable:    b.nullable,
+		Default:     b.def,
+		Constraints: b.constraints,
+	}
+	if b.specs == nil {
+		return d, errs
+	}
+
+	child := func(c *DataBuilder, path string) *DataDescription {
+		if c == nil {
+			errs = append(errs, prefixError(path, fmt.Errorf("DataBuilder: data could not be nil")))
+			return nil
+		}
+
+		cd, cerrs := c.description(path)
+		errs = append(errs, cerrs...)
+		return cd
+	}
+
+	switch specs := b.specs.(type) {
+	case *StructDataSpec:
+		specs.Fields = make(map[string]*DataDescription, len(b.fields))
+		specs.FieldOrder = b.fieldNames
+		for _, name := range b.fieldNames {
+			specs.Fields[name] = child(b.fields[name], joinPath(path, name))
+		}
+	case *ArrayDataSpec:
+		specs.Data = child(b.item, path+"[]")
+	case *MapDataSpec:
+		if b.key != nil {
+			specs.Key = child(b.key, joinPath(path, "<key>"))
+		}
+		specs.Value = child(b.value, joinPath(path, "<value>"))
+	case *UnionDataSpec:
+		specs.Alternatives = make([]*DataDescription, len(b.alternatives))
+		for i, alt := range b.alternatives {
+			specs.Alternatives[i] = child(alt, indexPath(path, i))
+		}
+	}
+
+	raw, err := json.Marshal(b.specs)
+	if err != nil {
+		errs = append(errs, prefixError(path, err))
+	}
+	d.SpecsRaw = raw
+	return d, errs
+}
+
+// configure 修改数据规格，fn返回false代表该设置不支持当前类型，引用命名类型时不能修改规格
+func (b *DataBuilder) configure(fn func(specs DataSpec) bool, method string) *DataBuilder {
+	if b.specs == nil {
+		// 类型不支持时已经记录了错误
+		if b.ref != "" {
+			b.unsupported(method)
+		}
+		return b
+	}
+
+	if !fn(b.specs) {
+		b.unsupported(method)
+	}
+	return b
+}
+
+func (b *DataBuilder) unsupported(method string) *DataBuilder {
+	if b.ref != "" {
+		return b.errorf("%s is not supported by ref [%s]", method, b.ref)
+	}
+	return b.errorf("%s is not supported by type [%s]", method, b.typ)
+}
+
+func (b *DataBuilder) errorf(format string, args ...interface{}) *DataBuilder {
+	b.errs = append(b.errs, fmt.Errorf("DataBuilder: "+format, args...))
+	return b
+}
+
+// decimal 解析十进制数字符串，为空时返回nil
+func (b *DataBuilder) decimal(method, s string) *decimal.Decimal {
+	if s == "" {
+		return nil
+	}
+
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		b.errorf("%s [%s] is not a decimal", method, s)
+		return nil
+	}
+	return &d
+}
+
+// PrefixErrors 为构造错误添加前缀，errors.Join合并的错误(包括嵌套合并的错误)逐个添加前缀，用于合并多个构造器的错误
+func PrefixErrors(prefix string, err error) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || prefix == "" {
+		return prefixError(prefix, err)
+	}
+
+	errs := joined.Unwrap()
+	prefixed := make([]error, len(errs))
+	for i, e := range errs {
+		prefixed[i] = PrefixErrors(prefix, e)
+	}
+	return errors.Join(prefixed...)
+}
+
+// prefixError 为错误添加数据路径前缀
+func prefixError(path string, err error) error {
+	if path == "" || err == nil {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
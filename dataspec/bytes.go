@@ -0,0 +1,120 @@
+package dataspec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// BytesEncoding 二进制数据在字符串中的编码方式
+type BytesEncoding string
+
+const (
+	// BytesBase64 标准base64编码，未设置时使用该编码
+	BytesBase64 BytesEncoding = "base64"
+
+	// BytesBase64URL URL安全的base64编码
+	BytesBase64URL BytesEncoding = "base64url"
+)
+
+// BytesDataSpec 二进制数据类型，例如证书、固件分片、原始传感器数据等，Go中使用[]byte，json中使用base64字符串，填充符可以省略
+//
+// 使用方式:
+//
+//	{
+//		"name": "certificate",
+//		"description": "设备证书",
+//		"data": {
+//			"type": "bytes",
+//			"specs": {
+//				"min_length": 1,
+//				"max_length": 4096,
+//				"encoding": "base64"
+//			}
+//		}
+//	}
+type BytesDataSpec struct {
+	// MinLength 最小字节数
+	MinLength int32 `json:"min_length,omitempty"`
+
+	// MaxLength 最大字节数，为零时不限制
+	MaxLength int32 `json:"max_length,omitempty"`
+
+	// Encoding 字符串的编码方式，支持 base64|base64url，若不设置则为base64
+	Encoding BytesEncoding `json:"encoding,omitempty"`
+}
+
+func (n *BytesDataSpec) parse() error {
+	switch n.Encoding {
+	case "":
+		n.Encoding = BytesBase64
+	case BytesBase64, BytesBase64URL:
+	default:
+		return fmt.Errorf("BytesDataSpecs: encoding [%s] is not supported", n.Encoding)
+	}
+
+	if n.MinLength < 0 || n.MaxLength < 0 {
+		return fmt.Errorf("BytesDataSpecs: length could not be negative")
+	}
+
+	if n.MaxLength != 0 && n.MinLength > n.MaxLength {
+		return fmt.Errorf("BytesDataSpecs: min_length could not be greater than max_length")
+	}
+	return nil
+}
+
+func (n *BytesDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+// ValidateBytes 验证二进制数据的长度
+func (n *BytesDataSpec) ValidateBytes(v []byte) (bool, error) {
+	r := &ValidationResult{}
+	n.checkBytes(r, "", v, v)
+	return r.result()
+}
+
+// DecodeString 按encoding解码字符串
+func (n *BytesDataSpec) DecodeString(v string) ([]byte, error) {
+	padded, raw := base64.StdEncoding, base64.RawStdEncoding
+	if n.Encoding == BytesBase64URL {
+		padded, raw = base64.URLEncoding, base64.RawURLEncoding
+	}
+
+	if len(v)%4 == 0 {
+		return padded.DecodeString(v)
+	}
+	return raw.DecodeString(v)
+}
+
+func (n *BytesDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.String:
+		b, err := n.DecodeString(v.String())
+		if err != nil {
+			r.addf(path, v.String(), &FormatError{Format: StringFormat(n.Encoding)},
+				"BytesDataSpecs: string must be encoded by [%s]", n.Encoding)
+			return
+		}
+		n.checkBytes(r, path, v.String(), b)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		n.checkBytes(r, path, reflectInterface(v), v.Bytes())
+	default:
+		r.addf(path, reflectInterface(v), newTypeError(BytesType, v), "BytesDataSpecs: value type is not supported")
+	}
+}
+
+func (n *BytesDataSpec) checkBytes(r *ValidationResult, path string, value interface{}, b []byte) {
+	max := int(n.MaxLength)
+	if max == 0 {
+		max = math.MaxInt32
+	}
+
+	if len(b) < int(n.MinLength) || len(b) > max {
+		r.addf(path, value, &LengthError{Min: int(n.MinLength), Max: max, Length: len(b)},
+			"BytesDataSpecs: bytes length must be range [%d, %d]", n.MinLength, max)
+	}
+}
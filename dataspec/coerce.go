@@ -0,0 +1,346 @@
+package dataspec
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CoerceMode 数据转换模式
+type CoerceMode int
+
+const (
+	// CoerceStrict 严格模式，仅转换数据的表示，不改变数据的类型，
+	// 例如int32转换为int64、没有小数的float64转换为int64、[]string转换为[]interface{}、Go结构体转换为map[string]interface{}
+	CoerceStrict CoerceMode = iota
+
+	// CoerceLenient 宽松模式，在严格模式的基础上允许跨类型转换，
+	// 例如字符串"25"转换为整数、1/0与"true"/"false"转换为布尔值、数值与布尔值转换为字符串
+	CoerceLenient
+)
+
+// Coerce 将数据转换为规格对应的标准Go类型，转换后的数据会再次验证
+//
+// 标准类型为: integer为int64(超过int64范围的无符号整数为uint64)，number为float64(步进误差在precision内时对齐步进)，
+// decimal为decimal.Decimal，boolean为bool，string为string，enum为int64或string，timestamp为format对应的字符串或int64，
+// bytes为[]byte，array为[]interface{}，struct与map为map[string]interface{}。
+// 转换或验证失败时返回原始数据，错误为*ValidationResult，包含所有错误及其路径
+func (d *DataDescription) Coerce(v interface{}, mode CoerceMode) (interface{}, error) {
+	r := &ValidationResult{}
+	out := coerceReflectData(d, reflect.ValueOf(v), "", mode, r)
+	if r.Valid() {
+		validateReflectData(d, reflect.ValueOf(out), "", r)
+	}
+
+	if !r.Valid() {
+		return v, r
+	}
+	return out, nil
+}
+
+// Normalize 同Coerce，使用严格模式
+func (d *DataDescription) Normalize(v interface{}) (interface{}, error) {
+	return d.Coerce(v, CoerceStrict)
+}
+
+func coerceReflectData(ds *DataDescription, v reflect.Value, path string, mode CoerceMode, r *ValidationResult) interface{} {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	// nil是否允许由验证决定
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch specs := ds.Specs.(type) {
+	case *StringDataSpec:
+		if s, ok := coerceString(v, mode); ok {
+			return s
+		}
+	case *IntegerDataSpec:
+		if i, ok := coerceInteger(v, mode); ok {
+			return i
+		}
+	case *NumericDataSpec:
+		if f, ok := specs.coerce(v, mode); ok {
+			return f
+		}
+	case *DecimalDataSpec:
+		if d, ok := coerceDecimal(v, mode); ok {
+			return d
+		}
+	case *BooleanDataSpec:
+		if b, ok := coerceBoolean(v, mode); ok {
+			return b
+		}
+	case *EnumDataSpec:
+		if specs.ValueType == StringType {
+			if s, ok := coerceString(v, mode); ok {
+				return s
+			}
+		} else if i, ok := coerceInteger(v, mode); ok {
+			return i
+		}
+	case *TimestampDataSpec:
+		if t, ok := specs.coerce(v, mode); ok {
+			return t
+		}
+	case *BytesDataSpec:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes()
+		} else if v.Kind() == reflect.String {
+			if b, err := specs.DecodeString(v.String()); err == nil {
+				return b
+			}
+		}
+	case *ArrayDataSpec:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			arr := make([]interface{}, v.Len())
+			for i := range arr {
+				arr[i] = coerceReflectData(specs.Data, v.Index(i), indexPath(path, i), mode, r)
+			}
+			return arr
+		}
+	case *StructDataSpec:
+		if m, ok := specs.coerce(v, path, mode, r); ok {
+			return m
+		}
+	case *MapDataSpec:
+		if v.Kind() == reflect.Map {
+			m := make(map[string]interface{}, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				key := fmt.Sprint(reflectInterface(iter.Key()))
+				m[key] = coerceReflectData(specs.Value, iter.Value(), joinPath(path, key), mode, r)
+			}
+			return m
+		}
+	case *UnionDataSpec:
+		return specs.coerce(v, path, mode)
+	default:
+		return reflectInterface(v)
+	}
+
+	r.addf(path, reflectInterface(v), newTypeError(ds.Type, v),
+		"DataSpecs: value [%s] could not be coerced to type [%s]", v.Kind().String(), ds.Type)
+	return reflectInterface(v)
+}
+
+// coerceString 转换为字符串，宽松模式下数值与布尔值会被格式化为字符串
+func coerceString(v reflect.Value, mode CoerceMode) (string, bool) {
+	if v.Kind() == reflect.String && v.Type() != jsonNumberType {
+		return v.String(), true
+	}
+
+	if mode != CoerceLenient {
+		return "", false
+	}
+
+	if v.Kind() == reflect.Bool {
+		return strconv.FormatBool(v.Bool()), true
+	}
+
+	if d, ok := reflectDecimal(v); ok {
+		return d.String(), true
+	}
+	return "", false
+}
+
+// coerceDecimal 转换为十进制数，字符串总是被接受，宽松模式下会忽略首尾的空白
+func coerceDecimal(v reflect.Value, mode CoerceMode) (decimal.Decimal, bool) {
+	if d, ok := reflectDecimal(v); ok {
+		return d, true
+	}
+
+	if v.Kind() != reflect.String {
+		return decimal.Decimal{}, false
+	}
+
+	s := v.String()
+	if mode == CoerceLenient {
+		s = strings.TrimSpace(s)
+	}
+
+	d, err := decimal.NewFromString(s)
+	return d, err == nil
+}
+
+// coerceInteger 转换为int64，超过int64范围的无符号整数转换为uint64，宽松模式下接受整数字符串
+func coerceInteger(v reflect.Value, mode CoerceMode) (interface{}, bool) {
+	d, ok := reflectIntegerDecimal(v)
+	if !ok && mode == CoerceLenient && v.Kind() == reflect.String {
+		d, ok = coerceDecimal(v, mode)
+		ok = ok && d.Equal(d.Truncate(0))
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	return integerValue(d), true
+}
+
+// integerValue 将整数转换为int64，超过int64范围时转换为uint64，超出uint64范围时保留decimal.Decimal，由验证报告错误
+func integerValue(d decimal.Decimal) interface{} {
+	if i := d.BigInt(); i.IsInt64() {
+		return i.Int64()
+	} else if i.IsUint64() {
+		return i.Uint64()
+	}
+	return d
+}
+
+// coerceBoolean 转换为布尔值，宽松模式下接受0、1以及strconv.ParseBool支持的字符串
+func coerceBoolean(v reflect.Value, mode CoerceMode) (bool, bool) {
+	if v.Kind() == reflect.Bool {
+		return v.Bool(), true
+	}
+
+	if mode != CoerceLenient {
+		return false, false
+	}
+
+	if v.Kind() == reflect.String && v.Type() != jsonNumberType {
+		b, err := strconv.ParseBool(strings.TrimSpace(v.String()))
+		return b, err == nil
+	}
+
+	if d, ok := reflectDecimal(v); ok {
+		switch {
+		case d.IsZero():
+			return false, true
+		case d.Equal(decimal.NewFromInt(1)):
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// coerce 转换为float64，与步进(或倍数)的误差在precision内时对齐步进，NaN与Inf保持不变
+func (n *NumericDataSpec) coerce(v reflect.Value, mode CoerceMode) (float64, bool) {
+	d, ok := reflectDecimal(v)
+	if !ok && mode == CoerceLenient && v.Kind() == reflect.String {
+		d, ok = coerceDecimal(v, mode)
+	}
+
+	if !ok {
+		if v.CanFloat() {
+			return v.Float(), true
+		}
+		return 0, false
+	}
+
+	precision := decimal.NewFromFloat(n.Precision)
+	if n.Step != 0 {
+		d = snapStep(d, n.stepBase(), decimal.NewFromFloat(n.Step), precision)
+	}
+	if n.MultipleOf != 0 {
+		d = snapStep(d, decimal.Zero, decimal.NewFromFloat(n.MultipleOf), precision)
+	}
+
+	f, _ := d.Float64()
+	return f, true
+}
+
+// snapStep 将v对齐到从base开始的最近步进，误差超过precision时保持不变
+func snapStep(v, base, step, precision decimal.Decimal) decimal.Decimal {
+	snapped := roundStep(v, base, step)
+	if v.Sub(snapped).Abs().GreaterThan(precision) {
+		return v
+	}
+	return snapped
+}
+
+// roundStep 将v对齐到从base开始的最近步进
+func roundStep(v, base, step decimal.Decimal) decimal.Decimal {
+	return v.Sub(base).Div(step).Round(0).Mul(step).Add(base)
+}
+
+// coerce 转换为format对应的表示，rfc3339为字符串，unix与unix_ms为int64，time.Time会被格式化
+func (n *TimestampDataSpec) coerce(v reflect.Value, mode CoerceMode) (interface{}, bool) {
+	if mode == CoerceLenient && n.Format != TimestampRFC3339 && v.Kind() == reflect.String {
+		i, ok := coerceInteger(v, mode)
+		if !ok {
+			return nil, false
+		}
+		v = reflect.ValueOf(i)
+	}
+
+	t, ok := n.reflectTime(v)
+	if !ok {
+		return nil, false
+	}
+
+	switch n.Format {
+	case TimestampUnix:
+		return t.Unix(), true
+	case TimestampUnixMilli:
+		return t.UnixMilli(), true
+	default:
+		return t.Format(time.RFC3339Nano), true
+	}
+}
+
+// coerce 转换为map[string]interface{}，按additional_fields策略处理未声明的字段，ignore策略下的字段会被丢弃
+func (a *StructDataSpec) coerce(v reflect.Value, path string, mode CoerceMode, r *ValidationResult) (map[string]interface{}, bool) {
+	m := make(map[string]interface{})
+	set := func(key string, value reflect.Value) {
+		dd, ok := a.Fields[key]
+		if !ok {
+			if a.AdditionalFields != AdditionalFieldsIgnore {
+				m[key] = reflectInterface(value)
+			}
+			return
+		}
+		m[key] = coerceReflectData(dd, value, joinPath(path, key), mode, r)
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			set(iter.Key().String(), iter.Value())
+		}
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := typ.Field(i)
+			if key := FieldName(field); key != "" && !FieldAbsent(field, v.Field(i)) {
+				set(key, v.Field(i))
+			}
+		}
+	default:
+		return nil, false
+	}
+	return m, true
+}
+
+// coerce 使用唯一符合的候选转换数据，没有或有多个候选符合时返回原始数据，由验证报告错误
+func (n *UnionDataSpec) coerce(v reflect.Value, path string, mode CoerceMode) interface{} {
+	var matched []interface{}
+	for _, alt := range n.Alternatives {
+		ar := &ValidationResult{}
+		out := coerceReflectData(alt, v, path, mode, ar)
+		if ar.Valid() {
+			validateReflectData(alt, reflect.ValueOf(out), path, ar)
+		}
+
+		if ar.Valid() {
+			matched = append(matched, out)
+		}
+	}
+
+	if len(matched) == 1 {
+		return matched[0]
+	}
+	return reflectInterface(v)
+}
@@ -0,0 +1,110 @@
+package dataspec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ConstraintFunc 自定义约束函数，v为待验证的数据，数据已经通过数据规格的验证，不满足约束时返回错误
+//
+// 返回*ValidationResult时，其中的错误路径会作为数据路径的子路径，例如结构体中校验和成员不匹配时可以返回 checksum 路径
+type ConstraintFunc func(v interface{}) error
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[string]ConstraintFunc{}
+)
+
+// RegisterConstraint 注册自定义约束，注册后数据描述可以通过constraints引用，通常在init中调用，名称已存在时返回错误
+//
+// 使用方式:
+//
+//	{
+//		"name": "serial",
+//		"data": {
+//			"type": "string",
+//			"constraints": ["luhn"],
+//			"specs": {
+//				"length": 16
+//			}
+//		}
+//	}
+func RegisterConstraint(name string, fn ConstraintFunc) error {
+	if name == "" {
+		return fmt.Errorf("DataSpecs: constraint name could not be empty")
+	}
+
+	if fn == nil {
+		return fmt.Errorf("DataSpecs: constraint [%s] could not be nil", name)
+	}
+
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+
+	if _, ok := constraints[name]; ok {
+		return fmt.Errorf("DataSpecs: constraint [%s] is already registered", name)
+	}
+	constraints[name] = fn
+	return nil
+}
+
+// LookupConstraint 获取已注册的自定义约束
+func LookupConstraint(name string) (ConstraintFunc, bool) {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+
+	fn, ok := constraints[name]
+	return fn, ok
+}
+
+// RegisteredConstraints 所有已注册的自定义约束名称，按名称排序
+func RegisteredConstraints() []string {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+
+	names := make([]string, 0, len(constraints))
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namedConstraint 解析后的自定义约束
+type namedConstraint struct {
+	name string
+	fn   ConstraintFunc
+}
+
+// parseConstraints 解析constraints，约束不存在时返回错误
+func (d *DataDescription) parseConstraints() error {
+	for _, name := range d.Constraints {
+		fn, ok := LookupConstraint(name)
+		if !ok {
+			return fmt.Errorf("DataDescription: constraint [%s] is not registered", name)
+		}
+		d.constraints = append(d.constraints, namedConstraint{name: name, fn: fn})
+	}
+	return nil
+}
+
+// checkConstraints 按顺序执行所有自定义约束，收集所有错误
+func (d *DataDescription) checkConstraints(r *ValidationResult, path string, v reflect.Value) {
+	value := reflectInterface(v)
+	for _, c := range d.constraints {
+		err := c.fn(value)
+		if err == nil {
+			continue
+		}
+
+		var vr *ValidationResult
+		if errors.As(err, &vr) {
+			mergeSubResult(r, path, vr)
+			continue
+		}
+		r.addf(path, value, &ConstraintError{Name: c.name, Err: err}, "DataSpecs: constraint [%s] failed, %s", c.name, err)
+	}
+}
@@ -1,6 +1,7 @@
 package dataspec
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -11,82 +12,144 @@ import (
 
 // DataDescription 数据描述，代表某个变量的数据元数据
 type DataDescription struct {
-	// Type 数据类型，目前支持 string|number|boolean|integer|array|struct
+	// Type 数据类型，目前支持 string|number|boolean|integer|enum|timestamp|bytes|array|decimal|struct|map|union|void，以及通过RegisterType注册的自定义类型
 	Type DataType `json:"type"`
 
-	// SpecsRaw 对应Type的数据类型，该字段不应该使用，仅作为解析使用
+	// SpecsRaw 对应Type的数据类型，该字段不应该使用，仅作为解析使用，省略时使用类型的默认规格
 	SpecsRaw json.RawMessage `json:"specs"`
 
 	// Specs 对应Type的数据类型，供外部使用
 	Specs DataSpec `json:"-"`
+
+	// Ref 引用的命名类型名称，设置后Type与Specs由命名类型决定，不能再设置specs，见Types
+	Ref string `json:"ref"`
+
+	// Required 作为结构体成员时，该成员是否必须存在，不设置时为可选成员
+	Required bool `json:"required"`
+
+	// Nullable 值是否可以为null
+	Nullable bool `json:"nullable"`
+
+	// Default 默认值，数据缺失时使用，必须符合数据规格，在Parse时验证并转换为规格对应的类型(见Coerce)
+	Default interface{} `json:"default"`
+
+	// Constraints 自定义约束名称，约束需要通过RegisterConstraint注册，在数据规格验证通过后按顺序执行
+	Constraints []string `json:"constraints"`
+
+	constraints []namedConstraint
 }
 
-func (d *DataDescription) parseStruct() error {
-	specs := StructDataSpec{}
-	if err := json.Unmarshal(d.SpecsRaw, &specs); err != nil {
-		return err
+// MarshalJSON 使用Specs而不是SpecsRaw输出规格，因此在Go中修改的Specs也会被输出，未解析时使用SpecsRaw，
+// 引用命名类型时仅输出ref，零值的字段不会被输出
+func (d *DataDescription) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Type        DataType        `json:"type,omitempty"`
+		Ref         string          `json:"ref,omitempty"`
+		Required    bool            `json:"required,omitempty"`
+		Nullable    bool            `json:"nullable,omitempty"`
+		Default     interface{}     `json:"default,omitempty"`
+		Constraints []string        `json:"constraints,omitempty"`
+		Specs       json.RawMessage `json:"specs,omitempty"`
+	}{
+		Ref:         d.Ref,
+		Required:    d.Required,
+		Nullable:    d.Nullable,
+		Default:     d.Default,
+		Constraints: d.Constraints,
 	}
-	d.Specs = specs
 
-	for _, spec := range specs {
-		if err := spec.Parse(); err != nil {
-			return err
+	if d.Ref == "" {
+		out.Type = d.Type
+		if d.Specs != nil {
+			specs, err := json.Marshal(d.Specs)
+			if err != nil {
+				return nil, err
+			}
+			out.Specs = specs
+		} else if len(d.SpecsRaw) != 0 {
+			out.Specs = d.SpecsRaw
 		}
 	}
-	return nil
+	return json.Marshal(out)
 }
 
-func (d *DataDescription) parseArray() error {
-	arr := &ArrayDataSpec{}
-	if err := json.Unmarshal(d.SpecsRaw, arr); err != nil {
+// UnmarshalJSON 默认值中的数值解析为json.Number而不是float64，避免超过2^53的整数丢失精度，在Parse时转换为规格对应的类型
+func (d *DataDescription) UnmarshalJSON(b []byte) error {
+	type dataDescription DataDescription
+	aux := struct {
+		*dataDescription
+		Default json.RawMessage `json:"default"`
+	}{dataDescription: (*dataDescription)(d)}
+	if err := json.Unmarshal(b, &aux); err != nil {
 		return err
 	}
-	d.Specs = arr
 
-	if arr.Length == 0 {
-		return fmt.Errorf("ArrayDataSpecs: array max length could not be zero")
+	d.Default = nil
+	if len(aux.Default) == 0 {
+		return nil
 	}
 
-	if arr.Data == nil {
-		return fmt.Errorf("ArrayDataSpecs: data field could not be empty")
+	dec := json.NewDecoder(bytes.NewReader(aux.Default))
+	dec.UseNumber()
+	return dec.Decode(&d.Default)
+}
+
+func (d *DataDescription) Parse() error {
+	return d.parse(nil)
+}
+
+// ParseWithTypes 同Parse，数据描述及其子数据描述中的ref从types中解析
+func (d *DataDescription) ParseWithTypes(types Types) error {
+	return d.parse(newTypeResolver(types))
+}
+
+func (d *DataDescription) parse(res *typeResolver) error {
+	d.constraints = nil
+	if d.Ref != "" {
+		if err := d.parseRef(res); err != nil {
+			return err
+		}
+	} else if err := d.parseSpecs(res); err != nil {
+		return err
 	}
 
-	if err := arr.Data.Parse(); err != nil {
+	if err := d.parseConstraints(); err != nil {
 		return err
 	}
-	return nil
-}
 
-func (d *DataDescription) Parse() error {
-	switch d.Type {
-	case NumberType:
-		d.Specs = &NumericDataSpec{
-			Min:       -math.MaxFloat64,
-			Max:       math.MaxFloat64,
-			Precision: 1e-12,
+	if d.Default != nil {
+		if r := d.Check(d.Default); !r.Valid() {
+			return fmt.Errorf("DataDescription: default value is invalid, %w", r)
 		}
-	case StringType:
-		d.Specs = &StringDataSpec{}
-	case IntegerType:
-		d.Specs = &IntegerDataSpec{
-			Min: math.MinInt64,
-			Max: math.MaxInt64,
+
+		// json中的数值解析为json.Number，转换为规格对应的类型，见Coerce
+		if v, err := d.Normalize(d.Default); err == nil {
+			d.Default = v
 		}
-	case BooleanType:
-		d.Specs = &BooleanDataSpec{}
-	case ArrayType:
-		return d.parseArray()
-	case StructType:
-		return d.parseStruct()
-	case VoidType:
-		d.Specs = &VoidDataSpec{}
-		return nil
-	default:
+	}
+	return nil
+}
+
+func (d *DataDescription) parseSpecs(res *typeResolver) error {
+	factory, ok := LookupType(d.Type)
+	if !ok {
 		return fmt.Errorf("could not parse description, type [%s] is not supported", d.Type)
 	}
 
-	if err := json.Unmarshal(d.SpecsRaw, d.Specs); err != nil {
-		return err
+	d.Specs = factory()
+	if len(d.SpecsRaw) != 0 {
+		if err := json.Unmarshal(d.SpecsRaw, d.Specs); err != nil {
+			return err
+		}
+	}
+
+	switch p := d.Specs.(type) {
+	case specParser:
+		return p.parse()
+	case nestedSpecParser:
+		return p.parse(res)
+	case SpecParser:
+		return p.ParseSpec()
 	}
 	return nil
 }
@@ -95,57 +158,68 @@ func (d *DataDescription) Validate(v interface{}) (bool, error) {
 	return validateData(d, v)
 }
 
-func validateReflectData(ds *DataDescription, v reflect.Value) (bool, error) {
+// Check 验证数据，返回包含所有错误及其路径的验证结果
+func (d *DataDescription) Check(v interface{}) *ValidationResult {
+	return d.CheckPath("", v)
+}
+
+// CheckPath 同Check，path作为所有错误路径的前缀，例如属性名称
+func (d *DataDescription) CheckPath(path string, v interface{}) *ValidationResult {
+	r := &ValidationResult{}
+	validateReflectData(d, reflect.ValueOf(v), path, r)
+	return r
+}
+
+func validateReflectData(ds *DataDescription, v reflect.Value, path string, r *ValidationResult) {
 	kind := v.Kind()
 	if kind == reflect.Interface || kind == reflect.Pointer {
 		v = v.Elem()
 	}
 
-	switch specs := ds.Specs.(type) {
-	case *StringDataSpec:
-		if v.Kind() == reflect.String {
-			return specs.ValidateString(v.String())
-		}
-	case *IntegerDataSpec:
-		if v.CanInt() {
-			return specs.ValidateInteger(v.Int())
-		} else if v.CanUint() {
-			return specs.ValidateInteger(int64(v.Uint()))
-		} else if v.CanFloat() {
-			// 这里为了解决json数据的整数情况，因为json是不存在整数的，所以当浮点没有小数点后的数，则为整数时，认为是整数
-			val := v.Float()
-			num := decimal.NewFromFloat(val)
-			rval := math.Round(val)
-			if !num.Equal(decimal.NewFromFloat(rval)) {
-				return false, fmt.Errorf("DataSpecs: type [%s] or value [%s] is not supported", ds.Type, v.Kind().String())
-			}
-			// 这里因为转换，为了保证能转换为整数，那么加上0.5，保证超过整数值
-			result := int64(rval + 0.5)
-			return specs.ValidateInteger(result)
-		}
-	case *NumericDataSpec:
-		if v.CanFloat() {
-			return specs.ValidateNumber(v.Float())
-		} else if v.CanInt() {
-			return specs.ValidateNumber(float64(v.Int()))
-		} else if v.CanUint() {
-			return specs.ValidateNumber(float64(v.Uint()))
+	if !v.IsValid() {
+		// void不需要传入数据，因此null总是合法的
+		if _, void := ds.Specs.(*VoidDataSpec); !void && !ds.Nullable {
+			r.addf(path, nil, ErrInvalidValue, "DataSpecs: value could not be null")
 		}
-	case *BooleanDataSpec:
-		if v.Kind() == reflect.Bool {
-			return true, nil
-		}
-	case *ArrayDataSpec:
-		return specs.Validate(v.Interface())
-	case StructDataSpec:
-		return specs.Validate(v.Interface())
-	case *VoidDataSpec:
-		return true, nil
-	}
-	return false, fmt.Errorf("DataSpecs: type [%s] or value [%s] is not supported", ds.Type, v.Kind().String())
+		return
+	}
+
+	n := len(r.Violations)
+	switch specs := ds.Specs.(type) {
+	case nil:
+		r.addf(path, reflectInterface(v), newTypeError(ds.Type, v),
+			"DataSpecs: type [%s] or value [%s] is not supported", ds.Type, v.Kind().String())
+	case specChecker:
+		specs.check(r, path, v)
+	default:
+		checkCustom(specs, r, path, v)
+	}
+
+	// 自定义约束仅在数据规格验证通过后执行
+	if len(r.Violations) == n {
+		ds.checkConstraints(r, path, v)
+	}
+}
+
+// reflectInteger 将反射值转换为int64，若值不是整数或超过int64范围，则返回false
+func reflectInteger(v reflect.Value) (int64, bool) {
+	d, ok := reflectIntegerDecimal(v)
+	if !ok || d.LessThan(decimal.NewFromInt(math.MinInt64)) || d.GreaterThan(decimal.NewFromInt(math.MaxInt64)) {
+		return 0, false
+	}
+	return d.IntPart(), true
+}
+
+// reflectIntegerDecimal 将反射值精确地转换为整数，支持所有整数、json.Number与decimal.Decimal，uint64不会溢出
+func reflectIntegerDecimal(v reflect.Value) (decimal.Decimal, bool) {
+	// 这里为了解决json数据的整数情况，因为json是不存在整数的，所以当浮点没有小数点后的数，则为整数时，认为是整数
+	d, ok := reflectDecimal(v)
+	if !ok || !d.Equal(d.Truncate(0)) {
+		return decimal.Decimal{}, false
+	}
+	return d, true
 }
 
 func validateData(ds *DataDescription, v interface{}) (bool, error) {
-	i := reflect.ValueOf(v)
-	return validateReflectData(ds, i)
+	return ds.Check(v).result()
 }
@@ -1,8 +1,25 @@
 package dataspec
 
+import "reflect"
+
 // DataSpec 数据规格接口
 type DataSpec interface {
 
 	// 验证接口，验证数据是否符合这个接口规格
 	Validate(v interface{}) (bool, error)
 }
+
+// specParser 数据规格解析接口，规格在反序列化后需要进一步检查或预处理时实现
+type specParser interface {
+	parse() error
+}
+
+// specChecker 内置数据规格的验证接口，验证反射值并收集带路径的错误
+type specChecker interface {
+	check(r *ValidationResult, path string, v reflect.Value)
+}
+
+// nestedSpecParser 包含子数据描述的数据规格解析接口，子数据描述可能引用命名类型，需要通过res解析引用
+type nestedSpecParser interface {
+	parse(res *typeResolver) error
+}
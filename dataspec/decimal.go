@@ -0,0 +1,165 @@
+package dataspec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	decimalType    = reflect.TypeOf(decimal.Decimal{})
+)
+
+// DecimalDataSpec 十进制定点数数据类型，用于金额、电量等不能有舍入误差的数据，
+// 数据可以为十进制字符串、json.Number、decimal.Decimal、整数或浮点数，小数位数不能超过scale
+//
+// 使用方式:
+//
+//	{
+//		"name": "balance",
+//		"description": "余额",
+//		"data": {
+//			"type": "decimal",
+//			"specs": {
+//				"min": "0",
+//				"max": "99999999.99",
+//				"scale": 2,
+//				"unit": "元"
+//			}
+//		}
+//	}
+type DecimalDataSpec struct {
+	// Min 最小值，可以为字符串或数字，若不设置则不限制
+	Min *decimal.Decimal `json:"min,omitempty"`
+
+	// Max 最大值，可以为字符串或数字，若不设置则不限制
+	Max *decimal.Decimal `json:"max,omitempty"`
+
+	// Step 步进，以Min为起点，若未设置Min则以零为起点，若不设置则不使用
+	Step *decimal.Decimal `json:"step,omitempty"`
+
+	// Scale 最大小数位数，若不设置则不限制
+	Scale *int32 `json:"scale,omitempty"`
+
+	// Unit 单位
+	Unit string `json:"unit,omitempty"`
+}
+
+func (n *DecimalDataSpec) parse() error {
+	if n.Min != nil && n.Max != nil && n.Min.GreaterThan(*n.Max) {
+		return fmt.Errorf("DecimalDataSpecs: min could not be greater than max")
+	}
+
+	if n.Step != nil && !n.Step.IsPositive() {
+		return fmt.Errorf("DecimalDataSpecs: step must be positive")
+	}
+
+	if n.Scale != nil && *n.Scale < 0 {
+		return fmt.Errorf("DecimalDataSpecs: scale could not be negative")
+	}
+	return nil
+}
+
+func (n *DecimalDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+// ValidateDecimal 验证十进制数
+func (n *DecimalDataSpec) ValidateDecimal(v decimal.Decimal) (bool, error) {
+	r := &ValidationResult{}
+	n.checkDecimal(r, "", v, v)
+	return r.result()
+}
+
+func (n *DecimalDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	d, ok := reflectDecimal(v)
+	if !ok && v.Kind() == reflect.String {
+		var err error
+		d, err = decimal.NewFromString(v.String())
+		ok = err == nil
+	}
+
+	if !ok {
+		r.addf(path, reflectInterface(v), newTypeError(DecimalType, v), "DecimalDataSpecs: value type is not supported")
+		return
+	}
+	n.checkDecimal(r, path, reflectInterface(v), d)
+}
+
+func (n *DecimalDataSpec) checkDecimal(r *ValidationResult, path string, value interface{}, v decimal.Decimal) {
+	if (n.Min != nil && v.LessThan(*n.Min)) || (n.Max != nil && v.GreaterThan(*n.Max)) {
+		err := &RangeError{Min: formatDecimal(n.Min), Max: formatDecimal(n.Max)}
+		r.addf(path, value, err, "DecimalDataSpecs: value must be range %s", err.Constraint())
+		return
+	}
+
+	if n.Scale != nil && !v.Equal(v.Truncate(*n.Scale)) {
+		r.addf(path, value, &ScaleError{Scale: *n.Scale}, "DecimalDataSpecs: value must have at most [%d] decimal places", *n.Scale)
+	}
+
+	if n.Step != nil {
+		if !v.Sub(n.stepBase()).Mod(*n.Step).IsZero() {
+			r.addf(path, value, &StepError{Step: n.Step.String()}, "DecimalDataSpecs: value must be step by [%s]", n.Step)
+		}
+	}
+}
+
+// stepBase 步进的起点，未设置最小值时以0为起点
+func (n *DecimalDataSpec) stepBase() decimal.Decimal {
+	if n.Min == nil {
+		return decimal.Zero
+	}
+	return *n.Min
+}
+
+// formatDecimal 格式化十进制数范围，为nil时代表不限制，返回空字符串
+func formatDecimal(d *decimal.Decimal) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+// decimalFromUint 将uint64转换为十进制数，不会溢出
+func decimalFromUint(v uint64) decimal.Decimal {
+	return decimal.NewFromBigInt(new(big.Int).SetUint64(v), 0)
+}
+
+// reflectDecimal 将反射值精确地转换为十进制数，支持整数、浮点数、json.Number与decimal.Decimal，普通字符串不被转换
+func reflectDecimal(v reflect.Value) (decimal.Decimal, bool) {
+	if !v.IsValid() {
+		return decimal.Decimal{}, false
+	}
+
+	switch {
+	case v.Type() == decimalType && v.CanInterface():
+		return v.Interface().(decimal.Decimal), true
+	case v.Type() == jsonNumberType:
+		d, err := decimal.NewFromString(v.String())
+		return d, err == nil
+	case v.CanInt():
+		return decimal.NewFromInt(v.Int()), true
+	case v.CanUint():
+		return decimalFromUint(v.Uint()), true
+	case v.Kind() == reflect.Float32:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return decimal.Decimal{}, false
+		}
+		return decimal.NewFromFloat32(float32(f)), true
+	case v.Kind() == reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return decimal.Decimal{}, false
+		}
+		return decimal.NewFromFloat(f), true
+	}
+	return decimal.Decimal{}, false
+}
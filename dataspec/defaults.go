@@ -0,0 +1,75 @@
+package dataspec
+
+// ApplyDefaults 使用默认值填充数据，返回填充后的数据
+//
+// v为nil时返回默认值；结构体数据中缺失的成员使用成员的默认值填充，数组与字典数据会对每个元素递归填充。
+// 由于Go结构体不存在缺失的字段，仅支持json解析得到的map[string]interface{}与[]interface{}，map会被原地修改
+func (d *DataDescription) ApplyDefaults(v interface{}) interface{} {
+	if v == nil {
+		return copyDefault(d.Default)
+	}
+
+	switch specs := d.Specs.(type) {
+	case *StructDataSpec:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+
+		for key, field := range specs.Fields {
+			value, ok := m[key]
+			if !ok {
+				if field.Default != nil {
+					m[key] = copyDefault(field.Default)
+				}
+				continue
+			}
+
+			if value != nil {
+				m[key] = field.ApplyDefaults(value)
+			}
+		}
+	case *ArrayDataSpec:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+
+		for i, elem := range arr {
+			if elem != nil {
+				arr[i] = specs.Data.ApplyDefaults(elem)
+			}
+		}
+	case *MapDataSpec:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+
+		for key, value := range m {
+			if value != nil {
+				m[key] = specs.Value.ApplyDefaults(value)
+			}
+		}
+	}
+	return v
+}
+
+// copyDefault 复制默认值，避免填充后的数据修改影响默认值本身
+func copyDefault(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = copyDefault(value)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, value := range v {
+			arr[i] = copyDefault(value)
+		}
+		return arr
+	}
+	return v
+}
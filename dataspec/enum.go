@@ -0,0 +1,128 @@
+package dataspec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// EnumDataSpec 枚举数据类型，值只能为声明的枚举值之一，每个枚举值对应一个描述，例如风速、工作模式等
+//
+// 使用方式:
+//
+//	{
+//		"name": "fan_speed",
+//		"description": "风速",
+//		"required": true,
+//		"data": {
+//			"type": "enum",
+//			"specs": {
+//				"value_type": "integer",
+//				"values": {
+//					"0": "低",
+//					"1": "中",
+//					"2": "高"
+//				}
+//			}
+//		}
+//	}
+type EnumDataSpec struct {
+	// ValueType 枚举值类型，支持 integer|string，若不设置则为integer
+	ValueType DataType `json:"value_type,omitempty"`
+
+	// Values 枚举值与对应的描述，key为枚举值，当ValueType为integer时，key必须为整数
+	Values map[string]string `json:"values"`
+
+	intValues map[int64]string
+}
+
+func (n *EnumDataSpec) parse() error {
+	if len(n.Values) == 0 {
+		return fmt.Errorf("EnumDataSpecs: values could not be empty")
+	}
+
+	switch n.ValueType {
+	case "":
+		n.ValueType = IntegerType
+		fallthrough
+	case IntegerType:
+		n.intValues = make(map[int64]string, len(n.Values))
+		for k, desc := range n.Values {
+			i, err := strconv.ParseInt(k, 10, 64)
+			if err != nil {
+				return fmt.Errorf("EnumDataSpecs: value [%s] is not an integer", k)
+			}
+			n.intValues[i] = desc
+		}
+	case StringType:
+	default:
+		return fmt.Errorf("EnumDataSpecs: value type [%s] is not supported", n.ValueType)
+	}
+	return nil
+}
+
+func (n *EnumDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+// ValidateInteger 验证整数是否为枚举值之一
+func (n *EnumDataSpec) ValidateInteger(v int64) (bool, error) {
+	r := &ValidationResult{}
+	n.checkInteger(r, "", v)
+	return r.result()
+}
+
+// ValidateString 验证字符串是否为枚举值之一
+func (n *EnumDataSpec) ValidateString(v string) (bool, error) {
+	r := &ValidationResult{}
+	n.checkString(r, "", v)
+	return r.result()
+}
+
+func (n *EnumDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	if n.ValueType == StringType {
+		if v.Kind() == reflect.String {
+			n.checkString(r, path, v.String())
+			return
+		}
+	} else if i, ok := reflectInteger(v); ok {
+		n.checkInteger(r, path, i)
+		return
+	}
+	r.addf(path, reflectInterface(v), newTypeError(n.ValueType, v), "EnumDataSpecs: value type is not supported")
+}
+
+func (n *EnumDataSpec) checkInteger(r *ValidationResult, path string, v int64) {
+	if n.ValueType == StringType {
+		r.addf(path, v, newTypeError(n.ValueType, reflect.ValueOf(v)), "EnumDataSpecs: value type is not supported")
+		return
+	}
+
+	if _, ok := n.intValues[v]; !ok {
+		r.addf(path, v, n.notAllowed(), "EnumDataSpecs: value [%d] is not allowed", v)
+	}
+}
+
+func (n *EnumDataSpec) checkString(r *ValidationResult, path string, v string) {
+	if n.ValueType != StringType {
+		r.addf(path, v, newTypeError(n.ValueType, reflect.ValueOf(v)), "EnumDataSpecs: value type is not supported")
+		return
+	}
+
+	if _, ok := n.Values[v]; !ok {
+		r.addf(path, v, n.notAllowed(), "EnumDataSpecs: value [%s] is not allowed", v)
+	}
+}
+
+// notAllowed 生成包含所有枚举值的错误，枚举值按顺序排列
+func (n *EnumDataSpec) notAllowed() *NotAllowedError {
+	keys := make([]string, 0, len(n.Values))
+	for k := range n.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return &NotAllowedError{Allowed: keys}
+}
@@ -0,0 +1,422 @@
+package dataspec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrTypeMismatch 数据类型与规格不匹配
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrOutOfRange 数值超出范围
+	ErrOutOfRange = errors.New("value out of range")
+
+	// ErrStepMismatch 数值不符合步进
+	ErrStepMismatch = errors.New("value does not match step")
+
+	// ErrLengthMismatch 长度不符合要求
+	ErrLengthMismatch = errors.New("length mismatch")
+
+	// ErrNotAllowed 值不在允许的取值中
+	ErrNotAllowed = errors.New("value not allowed")
+
+	// ErrUnknownField 结构体中存在未声明的字段
+	ErrUnknownField = errors.New("unknown field")
+
+	// ErrMissingField 结构体中缺少必须的字段
+	ErrMissingField = errors.New("missing field")
+
+	// ErrInvalidValue 值无效，例如结构体字段为nil
+	ErrInvalidValue = errors.New("invalid value")
+
+	// ErrNotUnique 数组元素重复
+	ErrNotUnique = errors.New("item not unique")
+
+	// ErrPatternMismatch 字符串不匹配正则表达式
+	ErrPatternMismatch = errors.New("pattern mismatch")
+
+	// ErrFormatMismatch 字符串不符合格式
+	ErrFormatMismatch = errors.New("format mismatch")
+
+	// ErrScaleExceeded 小数位数超过限制
+	ErrScaleExceeded = errors.New("scale exceeded")
+
+	// ErrUnionMismatch 联合数据不符合任何候选
+	ErrUnionMismatch = errors.New("no alternative matched")
+
+	// ErrUnionAmbiguous 联合数据符合多个候选
+	ErrUnionAmbiguous = errors.New("more than one alternative matched")
+
+	// ErrNotFinite 数值为NaN或±Inf，且规格不允许
+	ErrNotFinite = errors.New("value is not finite")
+
+	// ErrConstraintFailed 数据不满足自定义约束
+	ErrConstraintFailed = errors.New("constraint failed")
+
+	// ErrAssertionFailed 结构体不满足成员之间的断言
+	ErrAssertionFailed = errors.New("assertion failed")
+)
+
+// TypeError 数据类型不匹配错误，可通过errors.Is(err, ErrTypeMismatch)判断
+type TypeError struct {
+	// Expected 期望的数据类型
+	Expected DataType
+
+	// Actual 实际值的类型
+	Actual string
+}
+
+func newTypeError(expected DataType, v reflect.Value) *TypeError {
+	return &TypeError{Expected: expected, Actual: v.Kind().String()}
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("type mismatch, expected [%s] but got [%s]", e.Expected, e.Actual)
+}
+
+func (e *TypeError) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+// Constraint 违反的约束
+func (e *TypeError) Constraint() string {
+	return string(e.Expected)
+}
+
+// RangeError 数值超出范围错误，可通过errors.Is(err, ErrOutOfRange)判断
+type RangeError struct {
+	// Min 最小值
+	Min interface{}
+
+	// Max 最大值
+	Max interface{}
+
+	// ExclusiveMin 是否不包含最小值
+	ExclusiveMin bool
+
+	// ExclusiveMax 是否不包含最大值
+	ExclusiveMax bool
+}
+
+func (e *RangeError) Error() string {
+	return "value must be range " + e.Constraint()
+}
+
+func (e *RangeError) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+// Constraint 违反的约束
+func (e *RangeError) Constraint() string {
+	left, right := "[", "]"
+	if e.ExclusiveMin {
+		left = "("
+	}
+	if e.ExclusiveMax {
+		right = ")"
+	}
+	return fmt.Sprintf("%s%v, %v%s", left, e.Min, e.Max, right)
+}
+
+// StepError 数值不符合步进错误，可通过errors.Is(err, ErrStepMismatch)判断
+type StepError struct {
+	// Step 步进
+	Step interface{}
+}
+
+func (e *StepError) Error() string {
+	return "value must be step by " + e.Constraint()
+}
+
+func (e *StepError) Is(target error) bool {
+	return target == ErrStepMismatch
+}
+
+// Constraint 违反的约束
+func (e *StepError) Constraint() string {
+	return fmt.Sprintf("[%v]", e.Step)
+}
+
+// LengthError 长度错误，可通过errors.Is(err, ErrLengthMismatch)判断
+type LengthError struct {
+	// Min 最小长度
+	Min int
+
+	// Max 最大长度
+	Max int
+
+	// Length 实际长度
+	Length int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("length [%d] must be range %s", e.Length, e.Constraint())
+}
+
+func (e *LengthError) Is(target error) bool {
+	return target == ErrLengthMismatch
+}
+
+// Constraint 违反的约束
+func (e *LengthError) Constraint() string {
+	return fmt.Sprintf("[%d, %d]", e.Min, e.Max)
+}
+
+// NotAllowedError 值不在允许的取值中，可通过errors.Is(err, ErrNotAllowed)判断
+type NotAllowedError struct {
+	// Allowed 允许的取值
+	Allowed []string
+}
+
+func (e *NotAllowedError) Error() string {
+	return "value must be one of " + e.Constraint()
+}
+
+func (e *NotAllowedError) Is(target error) bool {
+	return target == ErrNotAllowed
+}
+
+// Constraint 违反的约束
+func (e *NotAllowedError) Constraint() string {
+	return "[" + strings.Join(e.Allowed, ", ") + "]"
+}
+
+// FieldError 结构体字段错误，Err为ErrUnknownField或ErrMissingField
+type FieldError struct {
+	// Field 字段名称
+	Field string
+
+	// Err 具体错误
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field [%s]: %s", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Constraint 违反的约束
+func (e *FieldError) Constraint() string {
+	return e.Field
+}
+
+// UniqueError 数组元素重复错误，可通过errors.Is(err, ErrNotUnique)判断
+type UniqueError struct {
+	// Key 判断是否重复的结构体成员，为空时代表整个元素
+	Key string
+
+	// Index 与之重复的元素下标
+	Index int
+}
+
+func (e *UniqueError) Error() string {
+	return fmt.Sprintf("item is duplicated with [%d], must be %s", e.Index, e.Constraint())
+}
+
+func (e *UniqueError) Is(target error) bool {
+	return target == ErrNotUnique
+}
+
+// Constraint 违反的约束
+func (e *UniqueError) Constraint() string {
+	if e.Key == "" {
+		return "unique"
+	}
+	return fmt.Sprintf("unique by [%s]", e.Key)
+}
+
+// PatternError 字符串不匹配正则表达式错误，可通过errors.Is(err, ErrPatternMismatch)判断
+type PatternError struct {
+	// Pattern 正则表达式
+	Pattern string
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("value must match pattern [%s]", e.Pattern)
+}
+
+func (e *PatternError) Is(target error) bool {
+	return target == ErrPatternMismatch
+}
+
+// Constraint 违反的约束
+func (e *PatternError) Constraint() string {
+	return e.Pattern
+}
+
+// FormatError 字符串不符合格式错误，可通过errors.Is(err, ErrFormatMismatch)判断
+type FormatError struct {
+	// Format 格式
+	Format StringFormat
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("value must be format [%s]", e.Format)
+}
+
+func (e *FormatError) Is(target error) bool {
+	return target == ErrFormatMismatch
+}
+
+// Constraint 违反的约束
+func (e *FormatError) Constraint() string {
+	return string(e.Format)
+}
+
+// ScaleError 小数位数超过限制错误，可通过errors.Is(err, ErrScaleExceeded)判断
+type ScaleError struct {
+	// Scale 最大小数位数
+	Scale int32
+}
+
+func (e *ScaleError) Error() string {
+	return fmt.Sprintf("value must have at most [%d] decimal places", e.Scale)
+}
+
+func (e *ScaleError) Is(target error) bool {
+	return target == ErrScaleExceeded
+}
+
+// Constraint 违反的约束
+func (e *ScaleError) Constraint() string {
+	return fmt.Sprintf("scale %d", e.Scale)
+}
+
+// UnionError 联合数据错误，不符合任何候选时可通过errors.Is(err, ErrUnionMismatch)判断，
+// 符合多个候选时可通过errors.Is(err, ErrUnionAmbiguous)判断
+type UnionError struct {
+	// Matched 符合的候选下标
+	Matched []int
+
+	// Failures 每个候选不符合的原因，下标与候选对应，符合的候选为nil
+	Failures []*ValidationResult
+}
+
+func (e *UnionError) Error() string {
+	if len(e.Matched) == 0 {
+		return "value does not match any alternative, " + e.reasons()
+	}
+	return fmt.Sprintf("value matches more than one alternative %v", e.Matched)
+}
+
+func (e *UnionError) Is(target error) bool {
+	if len(e.Matched) == 0 {
+		return target == ErrUnionMismatch
+	}
+	return target == ErrUnionAmbiguous
+}
+
+// Constraint 违反的约束
+func (e *UnionError) Constraint() string {
+	return "exactly one alternative"
+}
+
+// reasons 每个候选不符合的原因
+func (e *UnionError) reasons() string {
+	reasons := make([]string, 0, len(e.Failures))
+	for i, f := range e.Failures {
+		if f != nil {
+			reasons = append(reasons, fmt.Sprintf("alternative [%d]: %s", i, f.Error()))
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// ConstraintError 自定义约束错误，可通过errors.Is(err, ErrConstraintFailed)判断，Err为约束函数返回的错误
+type ConstraintError struct {
+	// Name 约束名称
+	Name string
+
+	// Err 约束函数返回的错误
+	Err error
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("constraint [%s] failed, %s", e.Name, e.Err)
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == ErrConstraintFailed
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// Constraint 违反的约束
+func (e *ConstraintError) Constraint() string {
+	return e.Name
+}
+
+// AssertionError 结构体断言错误，可通过errors.Is(err, ErrAssertionFailed)判断
+type AssertionError struct {
+	// Assertion 不成立的断言表达式
+	Assertion string
+}
+
+func (e *AssertionError) Error() string {
+	return "assertion failed: " + e.Assertion
+}
+
+func (e *AssertionError) Is(target error) bool {
+	return target == ErrAssertionFailed
+}
+
+// Constraint 违反的约束
+func (e *AssertionError) Constraint() string {
+	return e.Assertion
+}
+
+// violationCode 根据错误获取对应的错误码
+func violationCode(err error) ViolationCode {
+	switch {
+	case errors.Is(err, ErrTypeMismatch):
+		return CodeTypeMismatch
+	case errors.Is(err, ErrOutOfRange):
+		return CodeOutOfRange
+	case errors.Is(err, ErrStepMismatch):
+		return CodeStepMismatch
+	case errors.Is(err, ErrLengthMismatch):
+		return CodeLengthMismatch
+	case errors.Is(err, ErrNotAllowed):
+		return CodeNotAllowed
+	case errors.Is(err, ErrUnknownField):
+		return CodeUnknownField
+	case errors.Is(err, ErrMissingField):
+		return CodeMissingField
+	case errors.Is(err, ErrNotUnique):
+		return CodeNotUnique
+	case errors.Is(err, ErrPatternMismatch):
+		return CodePatternMismatch
+	case errors.Is(err, ErrFormatMismatch):
+		return CodeFormatMismatch
+	case errors.Is(err, ErrScaleExceeded):
+		return CodeScaleExceeded
+	case errors.Is(err, ErrUnionMismatch):
+		return CodeUnionMismatch
+	case errors.Is(err, ErrUnionAmbiguous):
+		return CodeUnionAmbiguous
+	case errors.Is(err, ErrNotFinite):
+		return CodeNotFinite
+	case errors.Is(err, ErrConstraintFailed):
+		return CodeConstraintFailed
+	case errors.Is(err, ErrAssertionFailed):
+		return CodeAssertionFailed
+	}
+	return CodeInvalidValue
+}
+
+// violationConstraint 获取错误携带的约束信息
+func violationConstraint(err error) string {
+	var c interface{ Constraint() string }
+	if errors.As(err, &c) {
+		return c.Constraint()
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package dataspec
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StringFormat 字符串的内置格式
+type StringFormat string
+
+const (
+	// FormatIPv4 IPv4地址，例如 192.168.1.1
+	FormatIPv4 StringFormat = "ipv4"
+
+	// FormatIPv6 IPv6地址，例如 fe80::1
+	FormatIPv6 StringFormat = "ipv6"
+
+	// FormatMAC MAC地址，例如 00:1a:2b:3c:4d:5e
+	FormatMAC StringFormat = "mac"
+
+	// FormatUUID UUID，例如 123e4567-e89b-12d3-a456-426614174000
+	FormatUUID StringFormat = "uuid"
+
+	// FormatEmail 邮箱地址，不包含显示名称
+	FormatEmail StringFormat = "email"
+
+	// FormatURI 包含scheme的绝对URI
+	FormatURI StringFormat = "uri"
+
+	// FormatHostname RFC 1123主机名
+	FormatHostname StringFormat = "hostname"
+
+	// FormatDateTime RFC 3339时间，例如 2006-01-02T15:04:05Z07:00
+	FormatDateTime StringFormat = "date-time"
+)
+
+var (
+	uuidRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+// stringFormats 内置格式的验证函数
+var stringFormats = map[StringFormat]func(v string) bool{
+	FormatIPv4: func(v string) bool {
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	},
+	FormatIPv6: func(v string) bool {
+		return net.ParseIP(v) != nil && strings.Contains(v, ":")
+	},
+	FormatMAC: func(v string) bool {
+		_, err := net.ParseMAC(v)
+		return err == nil
+	},
+	FormatUUID: uuidRegexp.MatchString,
+	FormatEmail: func(v string) bool {
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	},
+	FormatURI: func(v string) bool {
+		u, err := url.Parse(v)
+		return err == nil && u.IsAbs()
+	},
+	FormatHostname: func(v string) bool {
+		if len(v) == 0 || len(v) > 253 {
+			return false
+		}
+
+		for _, label := range strings.Split(strings.TrimSuffix(v, "."), ".") {
+			if !hostnameRegexp.MatchString(label) {
+				return false
+			}
+		}
+		return true
+	},
+	FormatDateTime: func(v string) bool {
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	},
+}
@@ -1,11 +1,42 @@
 package dataspec
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+
+	"github.com/shopspring/decimal"
 )
 
-// IntegerDataSpec 整数数据类型，包括有符号和无符号，正常应该使用有符合，因为数据范围更通用
+// IntegerFormat 整数格式，用于限制整数的位数与符号
+type IntegerFormat string
+
+const (
+	FormatInt8   IntegerFormat = "int8"
+	FormatInt16  IntegerFormat = "int16"
+	FormatInt32  IntegerFormat = "int32"
+	FormatInt64  IntegerFormat = "int64"
+	FormatUint8  IntegerFormat = "uint8"
+	FormatUint16 IntegerFormat = "uint16"
+	FormatUint32 IntegerFormat = "uint32"
+	FormatUint64 IntegerFormat = "uint64"
+)
+
+// integerFormats 每种格式对应的取值范围
+var integerFormats = map[IntegerFormat][2]decimal.Decimal{
+	FormatInt8:   {decimal.NewFromInt(math.MinInt8), decimal.NewFromInt(math.MaxInt8)},
+	FormatInt16:  {decimal.NewFromInt(math.MinInt16), decimal.NewFromInt(math.MaxInt16)},
+	FormatInt32:  {decimal.NewFromInt(math.MinInt32), decimal.NewFromInt(math.MaxInt32)},
+	FormatInt64:  {decimal.NewFromInt(math.MinInt64), decimal.NewFromInt(math.MaxInt64)},
+	FormatUint8:  {decimal.Zero, decimal.NewFromInt(math.MaxUint8)},
+	FormatUint16: {decimal.Zero, decimal.NewFromInt(math.MaxUint16)},
+	FormatUint32: {decimal.Zero, decimal.NewFromInt(math.MaxUint32)},
+	FormatUint64: {decimal.Zero, decimal.RequireFromString("18446744073709551615")},
+}
+
+// IntegerDataSpec 整数数据类型，包括有符号和无符号，正常应该使用有符合，因为数据范围更通用，
+// 设置format后，取值范围会被限制在格式的范围内，例如uint64可以表示超过Int64最大值的计数器
 //
 // 使用方式:
 //
@@ -23,45 +54,203 @@ import (
 //			}
 //		}
 //	}
+//
+//	{
+//		"name": "energy",
+//		"description": "累计电量",
+//		"data": {
+//			"type": "integer",
+//			"specs": {
+//				"format": "uint64",
+//				"unit": "Wh"
+//			}
+//		}
+//	}
+//
+//	{
+//		"name": "volume",
+//		"description": "音量，必须为5的倍数，不包含0",
+//		"data": {
+//			"type": "integer",
+//			"specs": {
+//				"min": 0,
+//				"max": 100,
+//				"exclusive_min": true,
+//				"multiple_of": 5
+//			}
+//		}
+//	}
 type IntegerDataSpec struct {
-	// Min 最小值，若不设置则会取Int64最小值
+	// Min 最小值，若不设置则会取Int64最小值，设置format时取两者中较大的值
 	Min int64 `json:"min"`
 
-	// Max 最大值，若不设置则会取Int64最大值
+	// Max 最大值，若不设置则会取Int64最大值，设置format时，若未设置则取格式的最大值，否则取两者中较小的值，
+	// 在Go中构造时，为Int64最大值的Max视为未设置
 	Max int64 `json:"max"`
 
-	// Step 步进，单步进为零时，不使用
-	Step int64 `json:"step"`
+	// ExclusiveMin 是否不包含最小值
+	ExclusiveMin bool `json:"exclusive_min,omitempty"`
+
+	// ExclusiveMax 是否不包含最大值
+	ExclusiveMax bool `json:"exclusive_max,omitempty"`
+
+	// Step 步进，单步进为零时，不使用，以Min为起点，若未设置Min则以0为起点
+	Step int64 `json:"step,omitempty"`
+
+	// MultipleOf 倍数，值必须为其整数倍，与Min无关，为零时不使用
+	MultipleOf int64 `json:"multiple_of,omitempty"`
 
 	// Unit 单位
-	Unit string `json:"unit"`
+	Unit string `json:"unit,omitempty"`
+
+	// Format 整数格式，支持 int8|int16|int32|int64|uint8|uint16|uint32|uint64，若不设置则不限制
+	Format IntegerFormat `json:"format,omitempty"`
+
+	// maxDeclared 是否显式设置了max，用于区分设置为Int64最大值的max与未设置的max
+	maxDeclared bool
+}
+
+// UnmarshalJSON 记录是否设置了max
+func (n *IntegerDataSpec) UnmarshalJSON(b []byte) error {
+	type integerDataSpec IntegerDataSpec
+	aux := struct {
+		Max *int64 `json:"max"`
+		*integerDataSpec
+	}{integerDataSpec: (*integerDataSpec)(n)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	n.maxDeclared = aux.Max != nil
+	if aux.Max != nil {
+		n.Max = *aux.Max
+	}
+	return nil
+}
+
+// MarshalJSON 未设置的min与max(即Int64的最小值与最大值，且max没有显式设置)不会被输出
+func (n *IntegerDataSpec) MarshalJSON() ([]byte, error) {
+	type integerDataSpec IntegerDataSpec
+	out := struct {
+		Min *int64 `json:"min,omitempty"`
+		Max *int64 `json:"max,omitempty"`
+		*integerDataSpec
+	}{integerDataSpec: (*integerDataSpec)(n)}
+
+	if n.Min != math.MinInt64 {
+		out.Min = &n.Min
+	}
+	if n.maxSet() {
+		out.Max = &n.Max
+	}
+	return json.Marshal(out)
+}
+
+func (n *IntegerDataSpec) parse() error {
+	if n.Format != "" {
+		if _, ok := integerFormats[n.Format]; !ok {
+			return fmt.Errorf("IntegerDataSpecs: format [%s] is not supported", n.Format)
+		}
+	}
+
+	if min, max := n.bounds(); min.GreaterThan(max) {
+		return fmt.Errorf("IntegerDataSpecs: min could not be greater than max")
+	}
+
+	if n.Step < 0 || n.MultipleOf < 0 {
+		return fmt.Errorf("IntegerDataSpecs: step and multiple_of could not be negative")
+	}
+	return nil
+}
+
+// bounds 实际的闭区间取值范围，即min、max(不包含时向内收缩1)与format范围的交集
+func (n *IntegerDataSpec) bounds() (decimal.Decimal, decimal.Decimal) {
+	min, max := decimal.NewFromInt(n.Min), decimal.NewFromInt(n.Max)
+	if n.ExclusiveMin {
+		min = min.Add(decimal.NewFromInt(1))
+	}
+	if n.ExclusiveMax {
+		max = max.Sub(decimal.NewFromInt(1))
+	}
+
+	if r, ok := integerFormats[n.Format]; ok {
+		if min.LessThan(r[0]) {
+			min = r[0]
+		}
+
+		if !n.maxSet() || max.GreaterThan(r[1]) {
+			max = r[1]
+		}
+	}
+	return min, max
+}
+
+// maxSet 是否设置了max，未设置时为Int64最大值
+func (n *IntegerDataSpec) maxSet() bool {
+	return n.maxDeclared || n.Max != math.MaxInt64
 }
 
 func (n *IntegerDataSpec) Validate(v interface{}) (bool, error) {
-	var result int64
-	value := reflect.ValueOf(v)
-	switch value.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		result = value.Int()
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		result = int64(value.Uint())
-	default:
-		return false, fmt.Errorf("value type is not supported")
-	}
-	return n.ValidateInteger(result)
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
 }
 
 func (n *IntegerDataSpec) ValidateInteger(v int64) (bool, error) {
-	if v < n.Min || v > n.Max {
-		return false, fmt.Errorf("IntegerDataSpecs: value must be range [%d, %d]", n.Min, n.Max)
+	r := &ValidationResult{}
+	n.checkDecimal(r, "", v, decimal.NewFromInt(v))
+	return r.result()
+}
+
+// ValidateUnsigned 验证无符号整数，超过Int64最大值的数据不会溢出
+func (n *IntegerDataSpec) ValidateUnsigned(v uint64) (bool, error) {
+	r := &ValidationResult{}
+	n.checkDecimal(r, "", v, decimalFromUint(v))
+	return r.result()
+}
+
+func (n *IntegerDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	d, ok := reflectIntegerDecimal(v)
+	if !ok {
+		r.addf(path, reflectInterface(v), newTypeError(IntegerType, v), "IntegerDataSpecs: value type is not supported")
+		return
+	}
+	n.checkDecimal(r, path, reflectInterface(v), d)
+}
+
+func (n *IntegerDataSpec) checkDecimal(r *ValidationResult, path string, value interface{}, v decimal.Decimal) {
+	min, max := n.bounds()
+	if v.LessThan(min) || v.GreaterThan(max) {
+		r.addf(path, value, &RangeError{Min: integerBound(min), Max: integerBound(max)},
+			"IntegerDataSpecs: value must be range [%s, %s]", min, max)
+		return
 	}
 
-	step := n.Step
-	if step != 0 {
-		dv := v - n.Min
-		if dv%step != 0 {
-			return false, fmt.Errorf("IntegerDataSpecs: value must be step by [%d]", step)
+	if step := n.Step; step != 0 {
+		if !v.Sub(n.stepBase()).Mod(decimal.NewFromInt(step)).IsZero() {
+			r.addf(path, value, &StepError{Step: step}, "IntegerDataSpecs: value must be step by [%d]", step)
 		}
 	}
-	return true, nil
+
+	if m := n.MultipleOf; m != 0 {
+		if !v.Mod(decimal.NewFromInt(m)).IsZero() {
+			r.addf(path, value, &StepError{Step: m}, "IntegerDataSpecs: value must be multiple of [%d]", m)
+		}
+	}
+}
+
+// stepBase 步进的起点，未设置最小值时以0为起点
+func (n *IntegerDataSpec) stepBase() decimal.Decimal {
+	if n.Min == math.MinInt64 {
+		return decimal.Zero
+	}
+	return decimal.NewFromInt(n.Min)
+}
+
+// integerBound 将范围转换为int64，超过int64范围时转换为uint64
+func integerBound(d decimal.Decimal) interface{} {
+	if d.IsNegative() || d.LessThanOrEqual(decimal.NewFromInt(math.MaxInt64)) {
+		return d.IntPart()
+	}
+	return d.BigInt().Uint64()
 }
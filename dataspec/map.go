@@ -0,0 +1,154 @@
+package dataspec
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// MapDataSpec 字典数据类型，与结构体不同，key不需要预先声明，例如按区域ID记录的温度，key仅支持string、integer或enum类型，
+// json中的key总是字符串，当key为integer时，字符串会被转换为整数后再验证
+//
+// 使用方式:
+//
+//	{
+//		"name": "zone_temperatures",
+//		"description": "各区域温度",
+//		"data": {
+//			"type": "map",
+//			"specs": {
+//				"max_size": 16,
+//				"key": {
+//					"type": "string",
+//					"specs": {
+//						"pattern": "^zone_[0-9]+$"
+//					}
+//				},
+//				"value": {
+//					"type": "number",
+//					"specs": {
+//						"min": -40,
+//						"max": 120
+//					}
+//				}
+//			}
+//		}
+//	}
+type MapDataSpec struct {
+	// Key key的数据描述，若不设置则为不限制的string
+	Key *DataDescription `json:"key"`
+
+	// Value 值的数据描述
+	Value *DataDescription `json:"value"`
+
+	// MinSize 最少条目数
+	MinSize int32 `json:"min_size,omitempty"`
+
+	// MaxSize 最多条目数，为零时不限制
+	MaxSize int32 `json:"max_size,omitempty"`
+}
+
+func (n *MapDataSpec) parse(res *typeResolver) error {
+	if n.Key == nil {
+		n.Key = &DataDescription{Type: StringType, SpecsRaw: []byte("{}")}
+	}
+
+	if err := n.Key.parse(res); err != nil {
+		return err
+	}
+
+	switch n.Key.Type {
+	case StringType, IntegerType, EnumType:
+	default:
+		return fmt.Errorf("MapDataSpecs: key type [%s] is not supported", n.Key.Type)
+	}
+
+	if n.Value == nil {
+		return fmt.Errorf("MapDataSpecs: value field could not be empty")
+	}
+
+	if err := n.Value.parse(res); err != nil {
+		return err
+	}
+
+	if n.MinSize < 0 || n.MaxSize < 0 {
+		return fmt.Errorf("MapDataSpecs: size could not be negative")
+	}
+
+	if n.MaxSize != 0 && n.MinSize > n.MaxSize {
+		return fmt.Errorf("MapDataSpecs: min_size could not be greater than max_size")
+	}
+	return nil
+}
+
+func (n *MapDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+func (n *MapDataSpec) check(r *ValidationResult, path string, value reflect.Value) {
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Map {
+		r.addf(path, reflectInterface(value), newTypeError(MapType, value), "MapDataSpecs: value type is not supported")
+		return
+	}
+
+	size := value.Len()
+	max := int(n.MaxSize)
+	if max == 0 {
+		max = math.MaxInt32
+	}
+
+	if size < int(n.MinSize) || size > max {
+		r.addf(path, reflectInterface(value), &LengthError{Min: int(n.MinSize), Max: max, Length: size},
+			"MapDataSpecs: map size must be range [%d, %d]", n.MinSize, max)
+	}
+
+	// 按key的字符串排序，保证错误顺序稳定
+	type entry struct {
+		name string
+		key  reflect.Value
+	}
+	entries := make([]entry, 0, size)
+	for _, k := range value.MapKeys() {
+		entries = append(entries, entry{name: fmt.Sprint(reflectInterface(k)), key: k})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	for _, e := range entries {
+		keyPath := joinPath(path, e.name)
+		n.checkKey(r, keyPath, e.key)
+		validateReflectData(n.Value, value.MapIndex(e.key), keyPath, r)
+	}
+}
+
+func (n *MapDataSpec) checkKey(r *ValidationResult, path string, k reflect.Value) {
+	if k.Kind() == reflect.Interface {
+		k = k.Elem()
+	}
+
+	isInteger := n.Key.Type == IntegerType
+	if specs, ok := n.Key.Specs.(*EnumDataSpec); ok {
+		isInteger = specs.ValueType != StringType
+	}
+
+	// json对象的key总是字符串，因此整数key需要先从字符串转换
+	if isInteger && k.Kind() == reflect.String {
+		i, err := strconv.ParseInt(k.String(), 10, 64)
+		if err != nil {
+			r.addf(path, k.String(), &TypeError{Expected: IntegerType, Actual: k.Kind().String()},
+				"MapDataSpecs: key [%s] is not an integer", k.String())
+			return
+		}
+		k = reflect.ValueOf(i)
+	}
+	validateReflectData(n.Key, k, path, r)
+}
@@ -1,9 +1,18 @@
 package dataspec
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
+
+	"github.com/shopspring/decimal"
+)
+
+// 默认取值范围对应的十进制数，MaxFloat64转换为十进制数的开销较大，因此只转换一次
+var (
+	minFloatDecimal = decimal.NewFromFloat(-math.MaxFloat64)
+	maxFloatDecimal = decimal.NewFromFloat(math.MaxFloat64)
 )
 
 // NumericDataSpec 数组数据类型，用于浮点数使用
@@ -24,6 +33,20 @@ import (
 //			}
 //		}
 //	}
+//
+//	{
+//		"name": "ratio",
+//		"description": "比例，不包含0",
+//		"data": {
+//			"type": "number",
+//			"specs": {
+//				"min": 0,
+//				"max": 1,
+//				"exclusive_min": true,
+//				"multiple_of": 0.05
+//			}
+//		}
+//	}
 type NumericDataSpec struct {
 	// Min 最小值，若不设置，使用double最小值
 	Min float64 `json:"min"`
@@ -31,44 +54,164 @@ type NumericDataSpec struct {
 	// Max 最大值，若不设置，使用double最大值
 	Max float64 `json:"max"`
 
-	// Step 步进, 若为零，则不使用
-	Step float64 `json:"step"`
+	// ExclusiveMin 是否不包含最小值
+	ExclusiveMin bool `json:"exclusive_min,omitempty"`
+
+	// ExclusiveMax 是否不包含最大值
+	ExclusiveMax bool `json:"exclusive_max,omitempty"`
+
+	// Step 步进, 若为零，则不使用，以Min为起点，若未设置Min则以0为起点
+	Step float64 `json:"step,omitempty"`
+
+	// MultipleOf 倍数，值必须为其整数倍，与Min无关，若为零，则不使用
+	MultipleOf float64 `json:"multiple_of,omitempty"`
 
 	// Unit 单位
-	Unit string `json:"unit"`
+	Unit string `json:"unit,omitempty"`
 
-	// Precision 精度
-	Precision float64 `json:"precision"`
+	// Precision 精度，步进检查允许的误差，由于使用十进制数精确计算，若不设置则不允许误差
+	Precision float64 `json:"precision,omitempty"`
+
+	// AllowNaN 是否允许NaN，默认不允许
+	AllowNaN bool `json:"allow_nan,omitempty"`
+
+	// AllowInf 是否允许±Inf，默认不允许，允许时±Inf同样需要符合取值范围，即对应的min或max未设置
+	AllowInf bool `json:"allow_inf,omitempty"`
 }
 
-func (n *NumericDataSpec) Validate(v interface{}) (bool, error) {
-	var result float64
-	value := reflect.ValueOf(v)
-	switch value.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		result = float64(value.Int())
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		result = float64(value.Uint())
-	case reflect.Float32, reflect.Float64:
-		result = value.Float()
-	default:
-		return false, fmt.Errorf("NumericDataSpecs: value type is not supported")
+// MarshalJSON 未设置的min与max(即double的最小值与最大值)不会被输出
+func (n *NumericDataSpec) MarshalJSON() ([]byte, error) {
+	type numericDataSpec NumericDataSpec
+	out := struct {
+		Min *float64 `json:"min,omitempty"`
+		Max *float64 `json:"max,omitempty"`
+		*numericDataSpec
+	}{numericDataSpec: (*numericDataSpec)(n)}
+
+	if n.Min != -math.MaxFloat64 {
+		out.Min = &n.Min
+	}
+	if n.Max != math.MaxFloat64 {
+		out.Max = &n.Max
+	}
+	return json.Marshal(out)
+}
+
+func (n *NumericDataSpec) parse() error {
+	if n.Min > n.Max || (n.Min == n.Max && (n.ExclusiveMin || n.ExclusiveMax)) {
+		return fmt.Errorf("NumericDataSpecs: min must be less than max")
+	}
+
+	if n.Step < 0 || n.MultipleOf < 0 {
+		return fmt.Errorf("NumericDataSpecs: step and multiple_of could not be negative")
 	}
-	return n.ValidateNumber(result)
+	return nil
+}
+
+func (n *NumericDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
 }
 
 func (n *NumericDataSpec) ValidateNumber(v float64) (bool, error) {
-	if v < n.Min || v > n.Max {
-		return false, fmt.Errorf("NumericDataSpecs: value must be range [%f, %f]", n.Min, n.Max)
+	r := &ValidationResult{}
+	n.checkNumber(r, "", v)
+	return r.result()
+}
+
+// ValidateDecimal 使用精确的十进制数验证，用于json.Number等超过浮点精度的数据
+func (n *NumericDataSpec) ValidateDecimal(v decimal.Decimal) (bool, error) {
+	r := &ValidationResult{}
+	n.checkDecimal(r, "", v, v)
+	return r.result()
+}
+
+func (n *NumericDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	if d, ok := reflectDecimal(v); ok {
+		n.checkDecimal(r, path, reflectInterface(v), d)
+	} else if v.CanFloat() {
+		n.checkNumber(r, path, v.Float())
+	} else {
+		r.addf(path, reflectInterface(v), newTypeError(NumberType, v), "NumericDataSpecs: value type is not supported")
+	}
+}
+
+func (n *NumericDataSpec) checkNumber(r *ValidationResult, path string, v float64) {
+	// NaN与Inf无法转换为十进制数，由AllowNaN与AllowInf决定是否允许
+	if math.IsNaN(v) {
+		if !n.AllowNaN {
+			r.addf(path, v, ErrNotFinite, "NumericDataSpecs: value could not be NaN")
+		}
+		return
 	}
 
-	step := n.Step
-	if math.Abs(step) > n.Precision {
-		dv := v - n.Min
-		s := math.Mod(dv, step)
-		if math.Abs(s-step) > n.Precision && s > n.Precision {
-			return false, fmt.Errorf("IntegerDataSpecs: value must be step by [%f]", step)
+	if math.IsInf(v, 0) {
+		if !n.AllowInf {
+			r.addf(path, v, ErrNotFinite, "NumericDataSpecs: value could not be infinite")
+		} else if !n.infInRange(v) {
+			err := &RangeError{Min: n.Min, Max: n.Max, ExclusiveMin: n.ExclusiveMin, ExclusiveMax: n.ExclusiveMax}
+			r.addf(path, v, err, "NumericDataSpecs: value must be range %s", err.Constraint())
 		}
+		return
 	}
-	return true, nil
+	n.checkDecimal(r, path, v, decimal.NewFromFloat(v))
+}
+
+// infInRange ±Inf是否符合取值范围，任何设置的边界(包括开区间)都不包含±Inf，因此只有对应的边界未设置时符合
+func (n *NumericDataSpec) infInRange(v float64) bool {
+	if v > 0 {
+		return n.Max == math.MaxFloat64
+	}
+	return n.Min == -math.MaxFloat64
+}
+
+// checkDecimal 使用十进制数检查范围与步进，避免浮点误差，例如 50.07 按 0.01 步进
+func (n *NumericDataSpec) checkDecimal(r *ValidationResult, path string, value interface{}, v decimal.Decimal) {
+	min := boundDecimal(n.Min)
+	max := boundDecimal(n.Max)
+	if v.LessThan(min) || v.GreaterThan(max) ||
+		(n.ExclusiveMin && v.Equal(min)) || (n.ExclusiveMax && v.Equal(max)) {
+		err := &RangeError{Min: n.Min, Max: n.Max, ExclusiveMin: n.ExclusiveMin, ExclusiveMax: n.ExclusiveMax}
+		r.addf(path, value, err, "NumericDataSpecs: value must be range %s", err.Constraint())
+		return
+	}
+
+	precision := decimal.NewFromFloat(n.Precision)
+	if math.Abs(n.Step) > n.Precision {
+		if !isMultiple(v.Sub(n.stepBase()), decimal.NewFromFloat(n.Step), precision) {
+			r.addf(path, value, &StepError{Step: n.Step}, "NumericDataSpecs: value must be step by [%v]", n.Step)
+		}
+	}
+
+	if n.MultipleOf > n.Precision {
+		if !isMultiple(v, decimal.NewFromFloat(n.MultipleOf), precision) {
+			r.addf(path, value, &StepError{Step: n.MultipleOf}, "NumericDataSpecs: value must be multiple of [%v]", n.MultipleOf)
+		}
+	}
+}
+
+// boundDecimal 将取值范围转换为十进制数，默认的±MaxFloat64使用预先转换的值
+func boundDecimal(f float64) decimal.Decimal {
+	switch f {
+	case -math.MaxFloat64:
+		return minFloatDecimal
+	case math.MaxFloat64:
+		return maxFloatDecimal
+	}
+	return decimal.NewFromFloat(f)
+}
+
+// stepBase 步进的起点，未设置最小值时以0为起点，否则以-MaxFloat64为起点的步进没有意义
+func (n *NumericDataSpec) stepBase() decimal.Decimal {
+	if n.Min == -math.MaxFloat64 {
+		return decimal.Zero
+	}
+	return decimal.NewFromFloat(n.Min)
+}
+
+// isMultiple 判断v是否为step的整数倍，允许precision的误差
+func isMultiple(v, step, precision decimal.Decimal) bool {
+	s := v.Mod(step).Abs()
+	return s.LessThanOrEqual(precision) || step.Abs().Sub(s).LessThanOrEqual(precision)
 }
@@ -0,0 +1,118 @@
+package dataspec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Types 命名的数据描述，key为类型名称，用于在物模型中复用数据描述，数据描述通过ref引用
+//
+// 使用方式:
+//
+//	"types": {
+//		"schedule": {
+//			"type": "struct",
+//			"specs": {
+//				"fields": {
+//					"start": {"type": "timestamp", "required": true, "specs": {}},
+//					"end": {"type": "timestamp", "required": true, "specs": {}}
+//				}
+//			}
+//		}
+//	}
+//
+//	"data": {
+//		"ref": "schedule",
+//		"required": true
+//	}
+//
+// 引用处的required、nullable与default优先使用引用处的设置，nullable为两者中任意一个，
+// 命名类型的constraints先于引用处的constraints执行
+type Types map[string]*DataDescription
+
+// Parse 解析所有命名数据描述，引用不存在或存在循环引用时返回错误
+func (t Types) Parse() error {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := newTypeResolver(t)
+	for _, name := range names {
+		if _, err := res.resolve(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeResolver 命名类型解析器，按需解析被引用的类型并检测循环引用
+type typeResolver struct {
+	types Types
+
+	// resolving 正在解析的类型，按引用顺序排列
+	resolving []string
+}
+
+func newTypeResolver(types Types) *typeResolver {
+	return &typeResolver{types: types}
+}
+
+// resolve 获取已解析的命名类型，未解析的类型会被解析，已有Specs的类型认为已经解析
+func (r *typeResolver) resolve(name string) (*DataDescription, error) {
+	if r == nil {
+		return nil, fmt.Errorf("DataDescription: ref [%s] could not be resolved without types", name)
+	}
+
+	t, ok := r.types[name]
+	if !ok || t == nil {
+		return nil, fmt.Errorf("DataDescription: ref [%s] is not defined in types", name)
+	}
+
+	for i, n := range r.resolving {
+		if n == name {
+			cycle := append(append([]string{}, r.resolving[i:]...), name)
+			return nil, fmt.Errorf("DataDescription: circular ref [%s]", strings.Join(cycle, " -> "))
+		}
+	}
+
+	if t.Specs != nil {
+		return t, nil
+	}
+
+	r.resolving = append(r.resolving, name)
+	err := t.parse(r)
+	r.resolving = r.resolving[:len(r.resolving)-1]
+	if err != nil {
+		t.Specs = nil
+		return nil, fmt.Errorf("DataDescription: type [%s] is invalid, %w", name, err)
+	}
+	return t, nil
+}
+
+// parseRef 使用引用的命名类型作为数据规格
+func (d *DataDescription) parseRef(res *typeResolver) error {
+	if len(d.SpecsRaw) != 0 && string(d.SpecsRaw) != "null" {
+		return fmt.Errorf("DataDescription: specs could not be used with ref [%s]", d.Ref)
+	}
+
+	t, err := res.resolve(d.Ref)
+	if err != nil {
+		return err
+	}
+
+	if d.Type != "" && d.Type != t.Type {
+		return fmt.Errorf("DataDescription: type [%s] does not match type [%s] of ref [%s]", d.Type, t.Type, d.Ref)
+	}
+
+	d.Type = t.Type
+	d.Specs = t.Specs
+	d.constraints = append(d.constraints, t.constraints...)
+	d.Nullable = d.Nullable || t.Nullable
+	if d.Default == nil {
+		d.Default = copyDefault(t.Default)
+	}
+	return nil
+}
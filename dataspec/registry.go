@@ -0,0 +1,126 @@
+package dataspec
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SpecFactory 数据规格工厂，返回设置了默认值的数据规格，Parse时specs会被反序列化到返回的数据规格中
+type SpecFactory func() DataSpec
+
+// SpecParser 自定义数据规格的解析接口，specs反序列化后需要进一步检查或预处理时实现，例如检查范围、编译正则表达式
+type SpecParser interface {
+	ParseSpec() error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[DataType]SpecFactory{
+		StringType: func() DataSpec { return &StringDataSpec{} },
+		IntegerType: func() DataSpec {
+			return &IntegerDataSpec{Min: math.MinInt64, Max: math.MaxInt64}
+		},
+		NumberType: func() DataSpec {
+			return &NumericDataSpec{Min: -math.MaxFloat64, Max: math.MaxFloat64}
+		},
+		BooleanType:   func() DataSpec { return &BooleanDataSpec{} },
+		EnumType:      func() DataSpec { return &EnumDataSpec{} },
+		TimestampType: func() DataSpec { return &TimestampDataSpec{} },
+		BytesType:     func() DataSpec { return &BytesDataSpec{} },
+		MapType:       func() DataSpec { return &MapDataSpec{} },
+		UnionType:     func() DataSpec { return &UnionDataSpec{} },
+		DecimalType:   func() DataSpec { return &DecimalDataSpec{} },
+		ArrayType:     func() DataSpec { return &ArrayDataSpec{} },
+		StructType:    func() DataSpec { return &StructDataSpec{} },
+		VoidType:      func() DataSpec { return &VoidDataSpec{} },
+	}
+)
+
+// RegisterType 注册自定义数据类型，注册后数据描述可以使用该类型，通常在init中调用
+//
+// 自定义数据规格通过Validate验证数据，Validate返回*ValidationResult时，其中的错误路径会作为数据路径的子路径，
+// 需要在反序列化后检查specs时可以实现SpecParser。类型名称已存在(包括内置类型)时返回错误
+func RegisterType(name DataType, factory SpecFactory) error {
+	if name == "" {
+		return fmt.Errorf("DataSpecs: type name could not be empty")
+	}
+
+	if factory == nil {
+		return fmt.Errorf("DataSpecs: factory of type [%s] could not be nil", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("DataSpecs: type [%s] is already registered", name)
+	}
+	registry[name] = factory
+	return nil
+}
+
+// LookupType 获取数据类型对应的数据规格工厂，包括内置类型
+func LookupType(name DataType) (SpecFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// RegisteredTypes 所有已注册的数据类型，包括内置类型，按名称排序
+func RegisteredTypes() []DataType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]DataType, 0, len(registry))
+	for name := range registry {
+		types = append(types, name)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	return types
+}
+
+// checkCustom 使用自定义数据规格的Validate验证数据，错误路径作为path的子路径
+func checkCustom(specs DataSpec, r *ValidationResult, path string, v reflect.Value) {
+	value := reflectInterface(v)
+	ok, err := specs.Validate(value)
+
+	var vr *ValidationResult
+	switch {
+	case errors.As(err, &vr):
+		mergeSubResult(r, path, vr)
+	case err != nil:
+		r.addf(path, value, err, "DataSpecs: %s", err)
+	case !ok:
+		r.addf(path, value, ErrInvalidValue, "DataSpecs: value is invalid")
+	}
+}
+
+// mergeSubResult 合并以相对路径表示的验证结果，错误路径作为path的子路径
+func mergeSubResult(r *ValidationResult, path string, sub *ValidationResult) {
+	for _, violation := range sub.Violations {
+		v := *violation
+		v.Path = subPath(path, violation.Path)
+		r.Add(&v)
+	}
+}
+
+// subPath 将相对路径拼接到path之后，例如 a 与 b.c 拼接为 a.b.c，a 与 [1] 拼接为 a[1]
+func subPath(path, sub string) string {
+	if sub == "" {
+		return path
+	}
+
+	if path == "" || strings.HasPrefix(sub, "[") {
+		return path + sub
+	}
+	return path + "." + sub
+}
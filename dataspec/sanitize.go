@@ -0,0 +1,292 @@
+package dataspec
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// Adjustment 修正记录，描述Sanitize对某个数据做的修改
+type Adjustment struct {
+	// Path 数据路径，例如 hello.age、temp[3]，为空时代表数据本身
+	Path string `json:"path"`
+
+	// From 修正前的值
+	From interface{} `json:"from"`
+
+	// To 修正后的值
+	To interface{} `json:"to"`
+
+	// Code 修正的原因，例如 out_of_range、step_mismatch、length_mismatch
+	Code ViolationCode `json:"code"`
+
+	// Message 修正信息
+	Message string `json:"message"`
+}
+
+func (a *Adjustment) String() string {
+	if a.Path == "" {
+		return a.Message
+	}
+	return a.Path + ": " + a.Message
+}
+
+type adjustments []*Adjustment
+
+func (a *adjustments) addf(path string, from, to interface{}, code ViolationCode, format string, args ...interface{}) {
+	*a = append(*a, &Adjustment{
+		Path:    path,
+		From:    from,
+		To:      to,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Sanitize 修正数据而不是拒绝数据，适用于存在噪声的传感器数据
+//
+// 数据先按严格模式转换为标准类型(见Coerce)，然后超出范围的数值与时间被限制到min/max(不包含的边界不会被限制)，
+// 不符合步进或倍数的数值对齐到最近的步进，超出小数位数的十进制数被舍入，超长的字符串与数组被截断，
+// 数组、结构体与字典会递归修正。返回修正后的数据与所有修正记录，
+// 无法修正的错误(例如类型错误、缺少成员)以*ValidationResult返回
+func (d *DataDescription) Sanitize(v interface{}) (interface{}, []*Adjustment, error) {
+	r := &ValidationResult{}
+	out := coerceReflectData(d, reflect.ValueOf(v), "", CoerceStrict, r)
+	if !r.Valid() {
+		return v, nil, r
+	}
+
+	var a adjustments
+	out = sanitizeData(d, out, "", &a)
+	validateReflectData(d, reflect.ValueOf(out), "", r)
+	return out, a, r.Err()
+}
+
+// sanitizeData 修正标准类型的数据，数据必须是coerceReflectData的结果
+func sanitizeData(ds *DataDescription, v interface{}, path string, a *adjustments) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	switch specs := ds.Specs.(type) {
+	case *IntegerDataSpec:
+		if d, ok := reflectIntegerDecimal(reflect.ValueOf(v)); ok {
+			return integerValue(specs.sanitize(a, path, d))
+		}
+	case *NumericDataSpec:
+		if f, ok := v.(float64); ok {
+			return specs.sanitize(a, path, f)
+		}
+	case *DecimalDataSpec:
+		if d, ok := v.(decimal.Decimal); ok {
+			return specs.sanitize(a, path, d)
+		}
+	case *StringDataSpec:
+		if s, ok := v.(string); ok {
+			return specs.sanitize(a, path, s)
+		}
+	case *TimestampDataSpec:
+		return specs.sanitize(a, path, v)
+	case *ArrayDataSpec:
+		if arr, ok := v.([]interface{}); ok {
+			return specs.sanitize(a, path, arr)
+		}
+	case *StructDataSpec:
+		if m, ok := v.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(m) {
+				if dd, ok := specs.Fields[key]; ok {
+					m[key] = sanitizeData(dd, m[key], joinPath(path, key), a)
+				}
+			}
+		}
+	case *MapDataSpec:
+		if m, ok := v.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(m) {
+				m[key] = sanitizeData(specs.Value, m[key], joinPath(path, key), a)
+			}
+		}
+	}
+	return v
+}
+
+func (n *IntegerDataSpec) sanitize(a *adjustments, path string, v decimal.Decimal) decimal.Decimal {
+	min, max := n.bounds()
+	v = clampDecimal(a, path, v, &min, &max, integerValue)
+	if n.Step != 0 {
+		v = snapDecimal(a, path, v, n.stepBase(), decimal.NewFromInt(n.Step), &min, &max, integerValue)
+	}
+
+	if n.MultipleOf != 0 {
+		v = snapDecimal(a, path, v, decimal.Zero, decimal.NewFromInt(n.MultipleOf), &min, &max, integerValue)
+	}
+	return v
+}
+
+func (n *NumericDataSpec) sanitize(a *adjustments, path string, v float64) float64 {
+	if math.IsNaN(v) || (math.IsInf(v, 0) && n.AllowInf && n.infInRange(v)) {
+		return v
+	}
+
+	var min, max *decimal.Decimal
+	if !n.ExclusiveMin {
+		d := boundDecimal(n.Min)
+		min = &d
+	}
+	if !n.ExclusiveMax {
+		d := boundDecimal(n.Max)
+		max = &d
+	}
+
+	// Inf无法转换为十进制数，直接限制到对应的边界
+	if math.IsInf(v, 0) {
+		to := n.Max
+		if v < 0 {
+			to = n.Min
+		}
+
+		if (v > 0 && max == nil) || (v < 0 && min == nil) {
+			return v
+		}
+
+		a.addf(path, v, to, CodeOutOfRange, "value clamped to [%v]", to)
+		v = to
+	}
+
+	conv := func(d decimal.Decimal) interface{} {
+		f, _ := d.Float64()
+		return f
+	}
+
+	d := clampDecimal(a, path, decimal.NewFromFloat(v), min, max, conv)
+	if n.Step != 0 {
+		d = snapDecimal(a, path, d, n.stepBase(), decimal.NewFromFloat(n.Step), min, max, conv)
+	}
+
+	if n.MultipleOf != 0 {
+		d = snapDecimal(a, path, d, decimal.Zero, decimal.NewFromFloat(n.MultipleOf), min, max, conv)
+	}
+
+	f, _ := d.Float64()
+	return f
+}
+
+func (n *DecimalDataSpec) sanitize(a *adjustments, path string, v decimal.Decimal) decimal.Decimal {
+	conv := func(d decimal.Decimal) interface{} {
+		return d
+	}
+
+	v = clampDecimal(a, path, v, n.Min, n.Max, conv)
+	if n.Scale != nil && !v.Equal(v.Round(*n.Scale)) {
+		to := v.Round(*n.Scale)
+		a.addf(path, v, to, CodeScaleExceeded, "value rounded to [%d] decimal places", *n.Scale)
+		v = to
+	}
+
+	if n.Step != nil {
+		v = snapDecimal(a, path, v, n.stepBase(), *n.Step, n.Min, n.Max, conv)
+	}
+	return v
+}
+
+func (n *StringDataSpec) sanitize(a *adjustments, path string, v string) string {
+	max := int(n.Length)
+	if max == 0 || n.stringLength(v) <= max {
+		return v
+	}
+
+	to := v
+	if n.LengthUnit == LengthUnitRune {
+		to = string([]rune(v)[:max])
+	} else {
+		// 按字节截断时不拆分UTF-8字符
+		end := max
+		for end > 0 && !utf8.RuneStart(v[end]) {
+			end--
+		}
+		to = v[:end]
+	}
+
+	a.addf(path, v, to, CodeLengthMismatch, "string truncated to length [%d]", max)
+	return to
+}
+
+func (n *TimestampDataSpec) sanitize(a *adjustments, path string, v interface{}) interface{} {
+	t, ok := n.ParseTime(v)
+	if !ok {
+		return v
+	}
+
+	var to time.Time
+	switch {
+	case n.Min != nil && t.Before(*n.Min):
+		to = *n.Min
+	case n.Max != nil && t.After(*n.Max):
+		to = *n.Max
+	default:
+		return v
+	}
+
+	out, _ := n.coerce(reflect.ValueOf(to), CoerceStrict)
+	a.addf(path, v, out, CodeOutOfRange, "time clamped to range %s", (&RangeError{Min: formatTime(n.Min), Max: formatTime(n.Max)}).Constraint())
+	return out
+}
+
+func (a *ArrayDataSpec) sanitize(adj *adjustments, path string, arr []interface{}) []interface{} {
+	for i, elem := range arr {
+		arr[i] = sanitizeData(a.Data, elem, indexPath(path, i), adj)
+	}
+
+	if _, max := a.lengthRange(); len(arr) > max {
+		adj.addf(path, arr, arr[:max], CodeLengthMismatch, "array truncated from [%d] to [%d] items", len(arr), max)
+		arr = arr[:max]
+	}
+	return arr
+}
+
+// clampDecimal 将v限制到[min, max]，min或max为nil时不限制对应的边界
+func clampDecimal(a *adjustments, path string, v decimal.Decimal, min, max *decimal.Decimal, conv func(decimal.Decimal) interface{}) decimal.Decimal {
+	to := v
+	switch {
+	case min != nil && v.LessThan(*min):
+		to = *min
+	case max != nil && v.GreaterThan(*max):
+		to = *max
+	default:
+		return v
+	}
+
+	a.addf(path, conv(v), conv(to), CodeOutOfRange, "value clamped to [%s]", to)
+	return to
+}
+
+// snapDecimal 将v对齐到从base开始的最近步进，对齐后超出范围时向范围内移动一个步进
+func snapDecimal(a *adjustments, path string, v, base, step decimal.Decimal, min, max *decimal.Decimal, conv func(decimal.Decimal) interface{}) decimal.Decimal {
+	to := roundStep(v, base, step)
+	if max != nil && to.GreaterThan(*max) {
+		to = to.Sub(step.Abs())
+	} else if min != nil && to.LessThan(*min) {
+		to = to.Add(step.Abs())
+	}
+
+	if to.Equal(v) {
+		return v
+	}
+
+	a.addf(path, conv(v), conv(to), CodeStepMismatch, "value snapped to step [%s]", step)
+	return to
+}
+
+// sortedKeys 排序后的key，保证修正记录的顺序稳定
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,6 +1,23 @@
 package dataspec
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+)
+
+// LengthUnit 字符串长度的计算单位
+type LengthUnit string
+
+const (
+	// LengthUnitByte 按字节计算长度，未设置时使用该单位
+	LengthUnitByte LengthUnit = "byte"
+
+	// LengthUnitRune 按UTF-8字符计算长度，例如中文
+	LengthUnitRune LengthUnit = "rune"
+)
 
 // StringDataSpec 字符串数据类型
 //
@@ -17,27 +34,134 @@ import "fmt"
 //			}
 //		}
 //	}
+//
+//	{
+//		"name": "device_name",
+//		"description": "设备名称，1到15个字符，可以为中文",
+//		"data": {
+//			"type": "string",
+//			"specs": {
+//				"min_length": 1,
+//				"length": 15,
+//				"length_unit": "rune",
+//				"pattern": "^[^\\s]+$"
+//			}
+//		}
+//	}
+//
+//	{
+//		"name": "mac",
+//		"description": "MAC地址",
+//		"data": {
+//			"type": "string",
+//			"specs": {
+//				"format": "mac"
+//			}
+//		}
+//	}
 type StringDataSpec struct {
-	// Length 字符串最大长度
-	Length int32 `json:"length"`
+	// Length 字符串最大长度，为零时不限制
+	Length int32 `json:"length,omitempty"`
+
+	// MinLength 字符串最小长度
+	MinLength int32 `json:"min_length,omitempty"`
+
+	// LengthUnit 长度的计算单位，支持 byte|rune，若不设置则为byte
+	LengthUnit LengthUnit `json:"length_unit,omitempty"`
+
+	// Pattern 字符串必须匹配的正则表达式，在Parse时编译
+	Pattern string `json:"pattern,omitempty"`
+
+	// Format 字符串格式，支持 ipv4|ipv6|mac|uuid|email|uri|hostname|date-time
+	Format StringFormat `json:"format,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+func (n *StringDataSpec) parse() error {
+	switch n.LengthUnit {
+	case "":
+		n.LengthUnit = LengthUnitByte
+	case LengthUnitByte, LengthUnitRune:
+	default:
+		return fmt.Errorf("StringDataSpecs: length unit [%s] is not supported", n.LengthUnit)
+	}
+
+	if n.Length < 0 || n.MinLength < 0 {
+		return fmt.Errorf("StringDataSpecs: length could not be negative")
+	}
+
+	if n.Length != 0 && n.MinLength > n.Length {
+		return fmt.Errorf("StringDataSpecs: min_length could not be greater than length")
+	}
+
+	if n.Pattern != "" {
+		pattern, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return fmt.Errorf("StringDataSpecs: pattern is invalid, %w", err)
+		}
+		n.pattern = pattern
+	}
+
+	if n.Format != "" {
+		if _, ok := stringFormats[n.Format]; !ok {
+			return fmt.Errorf("StringDataSpecs: format [%s] is not supported", n.Format)
+		}
+	}
+	return nil
 }
 
 func (n *StringDataSpec) Validate(v interface{}) (bool, error) {
 	str, ok := v.(string)
 	if !ok {
-		return false, fmt.Errorf("StringDataSpecs: value type is not supported")
+		r := &ValidationResult{}
+		r.addf("", v, newTypeError(StringType, reflect.ValueOf(v)), "StringDataSpecs: value type is not supported")
+		return r.result()
 	}
 
 	return n.ValidateString(str)
 }
 
+func (n *StringDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	if v.Kind() != reflect.String {
+		r.addf(path, reflectInterface(v), newTypeError(StringType, v), "StringDataSpecs: value type is not supported")
+		return
+	}
+	n.checkString(r, path, v.String())
+}
+
 func (n *StringDataSpec) ValidateString(v string) (bool, error) {
-	if n.Length == 0 {
-		return true, nil
+	r := &ValidationResult{}
+	n.checkString(r, "", v)
+	return r.result()
+}
+
+// stringLength 按规格的长度单位计算字符串长度
+func (n *StringDataSpec) stringLength(v string) int {
+	if n.LengthUnit == LengthUnitRune {
+		return utf8.RuneCountInString(v)
+	}
+	return len(v)
+}
+
+func (n *StringDataSpec) checkString(r *ValidationResult, path string, v string) {
+	l := n.stringLength(v)
+	if l < int(n.MinLength) || (n.Length != 0 && l > int(n.Length)) {
+		max := int(n.Length)
+		if max == 0 {
+			max = math.MaxInt32
+		}
+		r.addf(path, v, &LengthError{Min: int(n.MinLength), Max: max, Length: l},
+			"StringDataSpecs: string length must be range [%d, %d]", n.MinLength, max)
+	}
+
+	if n.pattern != nil && !n.pattern.MatchString(v) {
+		r.addf(path, v, &PatternError{Pattern: n.Pattern}, "StringDataSpecs: string must match pattern [%s]", n.Pattern)
 	}
 
-	if len(v) > int(n.Length) {
-		return false, fmt.Errorf("StringDataSpecs: string length must be range [%d, %d]", 0, n.Length)
+	if n.Format != "" {
+		if valid, ok := stringFormats[n.Format]; ok && !valid(v) {
+			r.addf(path, v, &FormatError{Format: n.Format}, "StringDataSpecs: string must be format [%s]", n.Format)
+		}
 	}
-	return true, nil
 }
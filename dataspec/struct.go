@@ -1,11 +1,31 @@
 package dataspec
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 )
 
-// StructDataSpec 结构体数据类型，该类型将spec当成一个子data进行处理，类似下列使用，specs每一个字段都为data
+// AdditionalFieldsPolicy 结构体中未声明字段的处理策略
+type AdditionalFieldsPolicy string
+
+const (
+	// AdditionalFieldsReject 存在未声明的字段时验证失败，未设置时使用该策略
+	AdditionalFieldsReject AdditionalFieldsPolicy = "reject"
+
+	// AdditionalFieldsIgnore 忽略未声明的字段，这些字段不会被验证，也不应该被使用，Coerce、Normalize与Sanitize的结果中会被丢弃
+	AdditionalFieldsIgnore AdditionalFieldsPolicy = "ignore"
+
+	// AdditionalFieldsAllow 允许未声明的字段，这些字段不会被验证，但会被保留，Coerce、Normalize与Sanitize的结果中原样保留
+	//
+	// 验证时ignore与allow相同，两者仅在转换后的数据中有区别
+	AdditionalFieldsAllow AdditionalFieldsPolicy = "allow"
+)
+
+// StructDataSpec 结构体数据类型，该类型将每个成员当成一个子data进行处理，成员的required为true时，该成员必须存在
 //
 // 使用方式:
 //
@@ -16,104 +36,386 @@ import (
 //		"data": {
 //			"type": "struct",
 //			"specs": {
-//				"name": {
-//					"type": "string",
-//					"specs": {
-//						"length": 15
-//					}
-//				},
-//				"age": {
-//					"type": "integer",
-//					"specs": {
-//						"min": 0,
-//						"max": 15,
-//						"step": 1,
-//						"unit": "y"
+//				"additional_fields": "ignore",
+//				"assertions": ["age >= 0 || name == 'unknown'"],
+//				"fields": {
+//					"name": {
+//						"type": "string",
+//						"required": true,
+//						"specs": {
+//							"length": 15
+//						}
+//					},
+//					"age": {
+//						"type": "integer",
+//						"specs": {
+//							"min": 0,
+//							"max": 15,
+//							"step": 1,
+//							"unit": "y"
+//						}
 //					}
 //				}
 //			}
 //		}
 //	}
-type StructDataSpec map[string]*DataDescription
+//
+// 为了兼容，当不需要设置additional_fields时，specs也可以直接为成员列表，例如
+//
+//	"specs": {
+//		"name": {
+//			"type": "string",
+//			"specs": {
+//				"length": 15
+//			}
+//		}
+//	}
+//
+// 旧版本中StructDataSpec为成员map，在Go中的成员map对应Fields，例如specs["name"]需要改为specs.Fields["name"]，
+// 构造时可以使用NewStructDataSpec转换
+type StructDataSpec struct {
+	// Fields 结构体成员，key为成员名称，用于按名称查找成员
+	Fields map[string]*DataDescription `json:"fields"`
 
-func (a StructDataSpec) Validate(v interface{}) (bool, error) {
-	value := reflect.ValueOf(v)
-	typ := value.Type()
-	kind := value.Kind()
+	// FieldOrder 成员的声明顺序，反序列化时按json中的顺序设置，序列化时按该顺序输出，见FieldNames
+	FieldOrder []string `json:"-"`
+
+	// AdditionalFields 未声明字段的处理策略，支持 reject|ignore|allow，若不设置则为reject
+	AdditionalFields AdditionalFieldsPolicy `json:"additional_fields"`
+
+	// Assertions 成员之间的断言，例如 start_time < end_time，在所有成员验证通过后按顺序检查，语法见assertion.go
+	Assertions []string `json:"assertions"`
+
+	assertions []*assertion
+}
+
+// NewStructDataSpec 使用成员列表创建结构体规格，用于迁移旧版本中直接使用成员map作为StructDataSpec的代码，
+// 成员按名称排序声明，未声明字段的处理策略为reject
+func NewStructDataSpec(fields map[string]*DataDescription) *StructDataSpec {
+	return &StructDataSpec{Fields: fields}
+}
+
+func (a *StructDataSpec) UnmarshalJSON(b []byte) error {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	// 成员列表形式的specs，此时fields只是一个普通的成员
+	fields, ok := raw["fields"]
+	if !ok || isDataDescription(fields) {
+		a.Fields = map[string]*DataDescription{}
+		if err := json.Unmarshal(b, &a.Fields); err != nil {
+			return err
+		}
+		fields = b
+	} else {
+		type structDataSpec StructDataSpec
+		if err := json.Unmarshal(b, (*structDataSpec)(a)); err != nil {
+			return err
+		}
+	}
+
+	order, err := objectKeys(fields)
+	if err != nil {
+		return err
+	}
+	a.FieldOrder = order
+	return nil
+}
+
+func (a *StructDataSpec) MarshalJSON() ([]byte, error) {
+	var fields bytes.Buffer
+	fields.WriteByte('{')
+	for i, name := range a.FieldNames() {
+		if i > 0 {
+			fields.WriteByte(',')
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(a.Fields[name])
+		if err != nil {
+			return nil, err
+		}
+
+		fields.Write(key)
+		fields.WriteByte(':')
+		fields.Write(value)
+	}
+	fields.WriteByte('}')
+
+	return json.Marshal(struct {
+		Fields           json.RawMessage        `json:"fields"`
+		AdditionalFields AdditionalFieldsPolicy `json:"additional_fields,omitempty"`
+		Assertions       []string               `json:"assertions,omitempty"`
+	}{
+		Fields:           fields.Bytes(),
+		AdditionalFields: a.AdditionalFields,
+		Assertions:       a.Assertions,
+	})
+}
+
+// FieldNames 按声明顺序返回所有成员名称，FieldOrder中不存在的成员(例如在Go中直接添加的成员)按名称排序放在最后
+func (a *StructDataSpec) FieldNames() []string {
+	names := make([]string, 0, len(a.Fields))
+	seen := make(map[string]bool, len(a.Fields))
+	for _, name := range a.FieldOrder {
+		if _, ok := a.Fields[name]; ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	rest := make([]string, 0, len(a.Fields)-len(names))
+	for name := range a.Fields {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// objectKeys 按顺序获取json对象的所有key
+func objectKeys(b []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, nil
+	}
+
+	keys := make([]string, 0)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// isDataDescription 判断json是否为数据描述，即type字段为字符串
+func isDataDescription(b []byte) bool {
+	var desc struct {
+		Type interface{} `json:"type"`
+	}
+	if err := json.Unmarshal(b, &desc); err != nil {
+		return false
+	}
+	_, ok := desc.Type.(string)
+	return ok
+}
+
+func (a *StructDataSpec) parse(res *typeResolver) error {
+	switch a.AdditionalFields {
+	case "":
+		a.AdditionalFields = AdditionalFieldsReject
+	case AdditionalFieldsReject, AdditionalFieldsIgnore, AdditionalFieldsAllow:
+	default:
+		return fmt.Errorf("StructDataSpecs: additional fields policy [%s] is not supported", a.AdditionalFields)
+	}
 
+	for _, name := range a.FieldNames() {
+		field := a.Fields[name]
+		if field == nil {
+			return fmt.Errorf("StructDataSpecs: field [%s] could not be empty", name)
+		}
+
+		if err := field.parse(res); err != nil {
+			return err
+		}
+	}
+
+	a.assertions = make([]*assertion, 0, len(a.Assertions))
+	for _, expr := range a.Assertions {
+		parsed, err := parseAssertion(expr, a.Fields)
+		if err != nil {
+			return fmt.Errorf("StructDataSpecs: assertion [%s] is invalid, %w", expr, err)
+		}
+		a.assertions = append(a.assertions, parsed)
+	}
+	return nil
+}
+
+func (a *StructDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	a.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+func (a *StructDataSpec) check(r *ValidationResult, path string, value reflect.Value) {
+	kind := value.Kind()
 	if kind == reflect.Pointer {
 		value = value.Elem()
-		typ = value.Type()
 		kind = value.Kind()
 	}
 
 	if kind != reflect.Map && kind != reflect.Struct {
-		return false, fmt.Errorf("StructDataSpecs: value type is not supported")
+		r.addf(path, reflectInterface(value), newTypeError(StructType, value), "StructDataSpecs: value type is not supported")
+		return
 	}
 
+	n := len(r.Violations)
+	present := make(map[string]bool, len(a.Fields))
 	if kind == reflect.Map {
+		if k := value.Type().Key(); k.Kind() != reflect.String {
+			r.addf(path, reflectInterface(value), &TypeError{Expected: StringType, Actual: k.Kind().String()},
+				"StructDataSpecs: type of key must be string")
+			return
+		}
+
 		iter := value.MapRange()
 		for iter.Next() {
-			k := iter.Key()
-			v := iter.Value()
-
-			if k.Kind() != reflect.String {
-				return false, fmt.Errorf("StructDataSpecs: type of key must be string")
+			key := iter.Key().String()
+			present[key] = true
+			a.checkField(r, path, key, iter.Value())
+		}
+	} else {
+		typ := value.Type()
+		numOfFields := value.NumField()
+		for i := 0; i < numOfFields; i++ {
+			field := typ.Field(i)
+			key := FieldName(field)
+			if key == "" || FieldAbsent(field, value.Field(i)) {
+				continue
 			}
+			present[key] = true
+			a.checkField(r, path, key, value.Field(i))
+		}
+	}
 
-			kind := v.Kind()
-			if kind == reflect.Interface || kind == reflect.Pointer {
-				v = v.Elem()
-				if !v.IsValid() {
-					return false, fmt.Errorf("StructDataSpecs: field is invalid or nil")
-				}
-			}
+	missing := make([]string, 0)
+	for key, dd := range a.Fields {
+		if dd.Required && !present[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		r.addf(joinPath(path, key), nil, &FieldError{Field: key, Err: ErrMissingField}, "StructDataSpecs: field [%s] is required", key)
+	}
 
-			key := k.String()
-			dd, ok := a[key]
-			if !ok {
-				return false, fmt.Errorf("StructDataSpecs: field [%s] is not allowed", key)
-			}
+	// 断言仅在所有成员验证通过后检查
+	if len(r.Violations) != n {
+		return
+	}
 
-			if ok, err := validateReflectData(dd, v); !ok {
-				return ok, err
-			}
+	for _, as := range a.assertions {
+		if !as.holds(value) {
+			r.addf(path, reflectInterface(value), &AssertionError{Assertion: as.expr},
+				"StructDataSpecs: assertion [%s] failed", as.expr)
 		}
-		return true, nil
 	}
+}
 
-	if kind == reflect.Struct {
-		numOfFields := value.NumField()
-		for i := 0; i < numOfFields; i++ {
-			value := value.Field(i)
-			field := typ.Field(i)
+func (a *StructDataSpec) checkField(r *ValidationResult, path string, key string, v reflect.Value) {
+	fieldPath := joinPath(path, key)
 
-			key := ""
-			if k, ok := field.Tag.Lookup("json"); ok {
-				key = k
-			} else {
-				key = field.Name
-			}
+	dd, ok := a.Fields[key]
+	if !ok {
+		if a.AdditionalFields == AdditionalFieldsIgnore || a.AdditionalFields == AdditionalFieldsAllow {
+			return
+		}
+		r.addf(fieldPath, reflectInterface(v), &FieldError{Field: key, Err: ErrUnknownField}, "StructDataSpecs: field [%s] is not allowed", key)
+		return
+	}
 
-			kind := value.Kind()
-			if kind == reflect.Interface || kind == reflect.Pointer {
-				value = value.Elem()
+	validateReflectData(dd, v, fieldPath, r)
+}
 
-				if !value.IsValid() {
-					return false, fmt.Errorf("StructDataSpecs: field is invalid or nil")
-				}
-			}
+// structMember 获取map或结构体中指定名称的成员，成员不存在时返回false
+func structMember(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
 
-			dd, ok := a[key]
-			if !ok {
-				return false, fmt.Errorf("StructDataSpecs: field [%s] is not allowed", key)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		member := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		return member, member.IsValid()
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if field := typ.Field(i); FieldName(field) == name {
+				return v.Field(i), !FieldAbsent(field, v.Field(i))
 			}
+		}
+	}
+	return reflect.Value{}, false
+}
 
-			if ok, err := validateReflectData(dd, value); !ok {
-				return ok, err
-			}
+// FieldName 获取结构体字段对应的数据名称，优先使用json标签中的名称，未导出或json标签为"-"的字段返回空字符串
+func FieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return field.Name
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// FieldAbsent 结构体字段是否视为缺失，即nil指针或接口，以及json标签包含omitempty的空值，与json序列化后缺失该字段一致
+func FieldAbsent(field reflect.StructField, v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return true
 		}
-		return true, nil
 	}
-	return false, fmt.Errorf("StructDataSpecs: argument type is not supported")
+
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return false
+	}
+
+	_, opts, _ := strings.Cut(tag, ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == "omitempty" {
+			return isEmptyValue(v)
+		}
+	}
+	return false
+}
+
+// isEmptyValue 与json的omitempty判断方式一致
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
 }
@@ -0,0 +1,137 @@
+package dataspec
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// TimestampFormat 时间在数据中的表示格式
+type TimestampFormat string
+
+const (
+	// TimestampRFC3339 RFC 3339字符串，例如 2006-01-02T15:04:05Z07:00，未设置时使用该格式
+	TimestampRFC3339 TimestampFormat = "rfc3339"
+
+	// TimestampUnix 秒级时间戳整数
+	TimestampUnix TimestampFormat = "unix"
+
+	// TimestampUnixMilli 毫秒级时间戳整数
+	TimestampUnixMilli TimestampFormat = "unix_ms"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// TimestampDataSpec 时间数据类型，数据按format声明的格式传输，Go结构体中也可以直接使用time.Time
+//
+// 使用方式:
+//
+//	{
+//		"name": "last_maintenance",
+//		"description": "上次维护时间",
+//		"data": {
+//			"type": "timestamp",
+//			"specs": {
+//				"format": "unix_ms",
+//				"min": "2020-01-01T00:00:00Z"
+//			}
+//		}
+//	}
+type TimestampDataSpec struct {
+	// Format 时间的表示格式，支持 rfc3339|unix|unix_ms，若不设置则为rfc3339
+	Format TimestampFormat `json:"format,omitempty"`
+
+	// Min 最早时间，RFC 3339格式，若不设置则不限制
+	Min *time.Time `json:"min,omitempty"`
+
+	// Max 最晚时间，RFC 3339格式，若不设置则不限制
+	Max *time.Time `json:"max,omitempty"`
+}
+
+func (n *TimestampDataSpec) parse() error {
+	switch n.Format {
+	case "":
+		n.Format = TimestampRFC3339
+	case TimestampRFC3339, TimestampUnix, TimestampUnixMilli:
+	default:
+		return fmt.Errorf("TimestampDataSpecs: format [%s] is not supported", n.Format)
+	}
+
+	if n.Min != nil && n.Max != nil && n.Min.After(*n.Max) {
+		return fmt.Errorf("TimestampDataSpecs: min could not be after max")
+	}
+	return nil
+}
+
+func (n *TimestampDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+// ValidateTime 验证时间是否在范围内
+func (n *TimestampDataSpec) ValidateTime(v time.Time) (bool, error) {
+	r := &ValidationResult{}
+	n.checkTime(r, "", v, v)
+	return r.result()
+}
+
+// ParseTime 按format将数据转换为时间，数据不符合格式时返回false
+func (n *TimestampDataSpec) ParseTime(v interface{}) (time.Time, bool) {
+	return n.reflectTime(reflect.ValueOf(v))
+}
+
+func (n *TimestampDataSpec) reflectTime(v reflect.Value) (time.Time, bool) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	if v.IsValid() && v.Type() == timeType && v.CanInterface() {
+		return v.Interface().(time.Time), true
+	}
+
+	switch n.Format {
+	case TimestampUnix, TimestampUnixMilli:
+		i, ok := reflectInteger(v)
+		if !ok {
+			return time.Time{}, false
+		}
+
+		if n.Format == TimestampUnix {
+			return time.Unix(i, 0), true
+		}
+		return time.UnixMilli(i), true
+	default:
+		if v.Kind() != reflect.String {
+			return time.Time{}, false
+		}
+
+		t, err := time.Parse(time.RFC3339, v.String())
+		return t, err == nil
+	}
+}
+
+func (n *TimestampDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	t, ok := n.reflectTime(v)
+	if !ok {
+		r.addf(path, reflectInterface(v), newTypeError(TimestampType, v),
+			"TimestampDataSpecs: value must be time or format [%s]", n.Format)
+		return
+	}
+	n.checkTime(r, path, reflectInterface(v), t)
+}
+
+func (n *TimestampDataSpec) checkTime(r *ValidationResult, path string, value interface{}, t time.Time) {
+	if (n.Min != nil && t.Before(*n.Min)) || (n.Max != nil && t.After(*n.Max)) {
+		err := &RangeError{Min: formatTime(n.Min), Max: formatTime(n.Max)}
+		r.addf(path, value, err, "TimestampDataSpecs: value must be range %s", err.Constraint())
+	}
+}
+
+// formatTime 格式化时间范围，为nil时代表不限制，返回空字符串
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
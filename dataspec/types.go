@@ -4,11 +4,17 @@ package dataspec
 type DataType string
 
 const (
-	StringType  DataType = "string"
-	IntegerType DataType = "integer"
-	NumberType  DataType = "number"
-	BooleanType DataType = "boolean"
-	ArrayType   DataType = "array"
-	StructType  DataType = "struct"
-	VoidType    DataType = "void"
+	StringType    DataType = "string"
+	IntegerType   DataType = "integer"
+	NumberType    DataType = "number"
+	BooleanType   DataType = "boolean"
+	EnumType      DataType = "enum"
+	TimestampType DataType = "timestamp"
+	BytesType     DataType = "bytes"
+	MapType       DataType = "map"
+	UnionType     DataType = "union"
+	DecimalType   DataType = "decimal"
+	ArrayType     DataType = "array"
+	StructType    DataType = "struct"
+	VoidType      DataType = "void"
 )
@@ -0,0 +1,123 @@
+package dataspec
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnionDataSpec 联合数据类型，数据必须符合且仅符合其中一个候选数据描述，用于不同形式的数据，例如单次或每周重复的定时。
+// 设置discriminator后，所有候选必须为包含该成员的结构体，仅验证该成员的值符合的候选
+//
+// 使用方式:
+//
+//	{
+//		"name": "schedule",
+//		"description": "定时",
+//		"data": {
+//			"type": "union",
+//			"specs": {
+//				"discriminator": "kind",
+//				"alternatives": [
+//					{
+//						"type": "struct",
+//						"specs": {
+//							"kind": {"type": "enum", "required": true, "specs": {"value_type": "string", "values": {"once": "单次"}}},
+//							"time": {"type": "timestamp", "required": true, "specs": {}}
+//						}
+//					},
+//					{
+//						"type": "struct",
+//						"specs": {
+//							"kind": {"type": "enum", "required": true, "specs": {"value_type": "string", "values": {"weekly": "每周"}}},
+//							"weekday": {"type": "integer", "required": true, "specs": {"min": 0, "max": 6}},
+//							"time": {"type": "string", "required": true, "specs": {"pattern": "^[0-2][0-9]:[0-5][0-9]$"}}
+//						}
+//					}
+//				]
+//			}
+//		}
+//	}
+type UnionDataSpec struct {
+	// Discriminator 用于区分候选的结构体成员名称，可以不设置
+	Discriminator string `json:"discriminator,omitempty"`
+
+	// Alternatives 候选数据描述
+	Alternatives []*DataDescription `json:"alternatives"`
+}
+
+func (n *UnionDataSpec) parse(res *typeResolver) error {
+	if len(n.Alternatives) == 0 {
+		return fmt.Errorf("UnionDataSpecs: alternatives could not be empty")
+	}
+
+	for i, alt := range n.Alternatives {
+		if alt == nil {
+			return fmt.Errorf("UnionDataSpecs: alternative [%d] could not be empty", i)
+		}
+
+		if err := alt.parse(res); err != nil {
+			return err
+		}
+
+		if n.Discriminator == "" {
+			continue
+		}
+
+		specs, ok := alt.Specs.(*StructDataSpec)
+		if !ok {
+			return fmt.Errorf("UnionDataSpecs: alternative [%d] must be struct when discriminator is set", i)
+		}
+
+		if _, ok := specs.Fields[n.Discriminator]; !ok {
+			return fmt.Errorf("UnionDataSpecs: alternative [%d] does not have discriminator [%s]", i, n.Discriminator)
+		}
+	}
+	return nil
+}
+
+func (n *UnionDataSpec) Validate(v interface{}) (bool, error) {
+	r := &ValidationResult{}
+	n.check(r, "", reflect.ValueOf(v))
+	return r.result()
+}
+
+func (n *UnionDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+	var discriminator reflect.Value
+	if n.Discriminator != "" {
+		member, ok := structMember(v, n.Discriminator)
+		if !ok {
+			r.addf(joinPath(path, n.Discriminator), nil, &FieldError{Field: n.Discriminator, Err: ErrMissingField},
+				"UnionDataSpecs: discriminator [%s] is required", n.Discriminator)
+			return
+		}
+		discriminator = member
+	}
+
+	err := &UnionError{Failures: make([]*ValidationResult, len(n.Alternatives))}
+	for i, alt := range n.Alternatives {
+		ar := &ValidationResult{}
+		if discriminator.IsValid() {
+			field := alt.Specs.(*StructDataSpec).Fields[n.Discriminator]
+			validateReflectData(field, discriminator, joinPath(path, n.Discriminator), ar)
+		}
+
+		if ar.Valid() {
+			validateReflectData(alt, v, path, ar)
+		}
+
+		if ar.Valid() {
+			err.Matched = append(err.Matched, i)
+		} else {
+			err.Failures[i] = ar
+		}
+	}
+
+	switch len(err.Matched) {
+	case 1:
+		return
+	case 0:
+		r.addf(path, reflectInterface(v), err, "UnionDataSpecs: value does not match any alternative, %s", err.reasons())
+	default:
+		r.addf(path, reflectInterface(v), err, "UnionDataSpecs: value matches more than one alternative %v", err.Matched)
+	}
+}
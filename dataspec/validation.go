@@ -0,0 +1,187 @@
+package dataspec
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ViolationCode 验证错误码，供外部程序识别错误类型使用
+type ViolationCode string
+
+const (
+	// CodeTypeMismatch 数据类型与规格不匹配
+	CodeTypeMismatch ViolationCode = "type_mismatch"
+
+	// CodeOutOfRange 数值超出范围
+	CodeOutOfRange ViolationCode = "out_of_range"
+
+	// CodeStepMismatch 数值不符合步进
+	CodeStepMismatch ViolationCode = "step_mismatch"
+
+	// CodeLengthMismatch 长度不符合要求，包括字符串长度和数组长度
+	CodeLengthMismatch ViolationCode = "length_mismatch"
+
+	// CodeNotAllowed 值不在允许的取值中，例如枚举值
+	CodeNotAllowed ViolationCode = "not_allowed"
+
+	// CodeUnknownField 结构体中存在未声明的字段
+	CodeUnknownField ViolationCode = "unknown_field"
+
+	// CodeMissingField 结构体中缺少必须的字段
+	CodeMissingField ViolationCode = "missing_field"
+
+	// CodeNotUnique 数组元素重复
+	CodeNotUnique ViolationCode = "not_unique"
+
+	// CodePatternMismatch 字符串不匹配正则表达式
+	CodePatternMismatch ViolationCode = "pattern_mismatch"
+
+	// CodeFormatMismatch 字符串不符合格式，例如ipv4、mac
+	CodeFormatMismatch ViolationCode = "format_mismatch"
+
+	// CodeScaleExceeded 十进制数的小数位数超过限制
+	CodeScaleExceeded ViolationCode = "scale_exceeded"
+
+	// CodeUnionMismatch 联合数据不符合任何候选
+	CodeUnionMismatch ViolationCode = "union_mismatch"
+
+	// CodeUnionAmbiguous 联合数据符合多个候选
+	CodeUnionAmbiguous ViolationCode = "union_ambiguous"
+
+	// CodeNotFinite 数值为NaN或±Inf
+	CodeNotFinite ViolationCode = "not_finite"
+
+	// CodeConstraintFailed 数据不满足自定义约束
+	CodeConstraintFailed ViolationCode = "constraint_failed"
+
+	// CodeAssertionFailed 结构体不满足成员之间的断言
+	CodeAssertionFailed ViolationCode = "assertion_failed"
+
+	// CodeInvalidValue 值无效，例如结构体字段为nil
+	CodeInvalidValue ViolationCode = "invalid_value"
+
+	// CodeNotFound 属性、动作或事件不存在
+	CodeNotFound ViolationCode = "not_found"
+
+	// CodeAccessDenied 属性的访问模式不允许该操作，例如写入只读属性
+	CodeAccessDenied ViolationCode = "access_denied"
+)
+
+// Violation 验证错误，描述某个数据违反的约束
+type Violation struct {
+	// Path 数据路径，例如 hello.age、temp[3]，为空时代表数据本身
+	Path string `json:"path"`
+
+	// Value 违反约束的值
+	Value interface{} `json:"value"`
+
+	// Code 错误码
+	Code ViolationCode `json:"code"`
+
+	// Constraint 违反的约束，例如范围 [0, 15]、步进 2、长度 5、类型 integer
+	Constraint string `json:"constraint"`
+
+	// Message 错误信息
+	Message string `json:"message"`
+
+	// Err 具体错误，携带约束的详细信息，例如*RangeError，可使用errors.Is、errors.As判断
+	Err error `json:"-"`
+}
+
+func (v *Violation) Error() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// ValidationResult 验证结果，与Validate不同，验证不会在第一个错误时停止，而是收集所有错误
+type ValidationResult struct {
+	// Violations 所有验证错误
+	Violations []*Violation `json:"violations"`
+}
+
+// Valid 是否验证通过
+func (r *ValidationResult) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// Add 添加验证错误
+func (r *ValidationResult) Add(v *Violation) {
+	r.Violations = append(r.Violations, v)
+}
+
+// Merge 合并另一个验证结果的所有错误
+func (r *ValidationResult) Merge(o *ValidationResult) {
+	if o == nil {
+		return
+	}
+	r.Violations = append(r.Violations, o.Violations...)
+}
+
+// Err 验证通过时返回nil，否则返回验证结果本身作为错误
+func (r *ValidationResult) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	return r
+}
+
+func (r *ValidationResult) Error() string {
+	msgs := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		msgs = append(msgs, v.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap 返回所有验证错误，用于errors.Is和errors.As
+func (r *ValidationResult) Unwrap() []error {
+	errs := make([]error, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		errs = append(errs, v)
+	}
+	return errs
+}
+
+func (r *ValidationResult) result() (bool, error) {
+	return r.Valid(), r.Err()
+}
+
+func (r *ValidationResult) addf(path string, value interface{}, err error, format string, args ...interface{}) {
+	r.Add(&Violation{
+		Path:       path,
+		Value:      value,
+		Code:       violationCode(err),
+		Constraint: violationConstraint(err),
+		Message:    fmt.Sprintf(format, args...),
+		Err:        err,
+	})
+}
+
+// joinPath 拼接结构体字段路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// indexPath 拼接数组下标路径
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// reflectInterface 获取反射值对应的原始值，无法获取时返回nil
+func reflectInterface(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -1,5 +1,7 @@
 package dataspec
 
+import "reflect"
+
 // VoidDataSpec 空数据描述，当为该类型时，代表不需要传入数据，void的spec将被忽略
 type VoidDataSpec struct {
 }
@@ -7,3 +9,11 @@ type VoidDataSpec struct {
 func (n *VoidDataSpec) Validate(v interface{}) (bool, error) {
 	return true, nil
 }
+
+// UnmarshalJSON void的spec将被忽略
+func (n *VoidDataSpec) UnmarshalJSON(b []byte) error {
+	return nil
+}
+
+func (n *VoidDataSpec) check(r *ValidationResult, path string, v reflect.Value) {
+}
@@ -0,0 +1,20 @@
+package thingmodel
+
+import "errors"
+
+var (
+	// ErrPropertyNotFound 属性不存在
+	ErrPropertyNotFound = errors.New("property not found")
+
+	// ErrPropertyNotWritable 属性不可写，例如云端设置只读的传感器属性
+	ErrPropertyNotWritable = errors.New("property is not writable")
+
+	// ErrPropertyNotReadable 属性不可读，例如设备上报只写的属性
+	ErrPropertyNotReadable = errors.New("property is not readable")
+
+	// ErrActionNotFound 动作不存在
+	ErrActionNotFound = errors.New("action not found")
+
+	// ErrEventNotFound 事件不存在
+	ErrEventNotFound = errors.New("event not found")
+)
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"github.com/AtomPod/thingmodel/thingmodel/dataspec"
+)
+
+// EventBuilder 事件描述构造器
+type EventBuilder struct {
+	desc EventDescription
+	data *dataspec.DataBuilder
+}
+
+// NewEvent 创建事件描述构造器
+func NewEvent(name string, typ EventType, data *dataspec.DataBuilder) *EventBuilder {
+	return &EventBuilder{
+		desc: EventDescription{Name: name, Type: typ},
+		data: data,
+	}
+}
+
+// Name 事件名称
+func (b *EventBuilder) Name() string {
+	return b.desc.Name
+}
+
+// Describe 事件描述，作为解释说明
+func (b *EventBuilder) Describe(text string) *EventBuilder {
+	b.desc.Description = text
+	return b
+}
+
+// Description 返回未解析的事件描述，以及构造过程中的所有错误
+func (b *EventBuilder) Description() (*EventDescription, error) {
+	desc := b.desc
+	if b.data == nil {
+		return &desc, nil
+	}
+
+	data, err := b.data.Description()
+	desc.Data = data
+	return &desc, dataspec.PrefixErrors("data", err)
+}
+
+// Build 返回解析后的事件描述
+func (b *EventBuilder) Build() (*EventDescription, error) {
+	desc, err := b.Description()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := desc.UpdateData(); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
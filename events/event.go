@@ -22,6 +22,11 @@ type EventDescription struct {
 }
 
 func (e *EventDescription) UpdateData() error {
+	return e.UpdateDataWithTypes(nil)
+}
+
+// UpdateDataWithTypes 同UpdateData，数据描述中的ref从types中解析
+func (e *EventDescription) UpdateDataWithTypes(types dataspec.Types) error {
 	if len(e.Name) == 0 {
 		return fmt.Errorf("EventDescription: name could not be empty")
 	}
@@ -30,7 +35,7 @@ func (e *EventDescription) UpdateData() error {
 		return fmt.Errorf("EventDescription: data field could not be empty")
 	}
 
-	if err := e.Data.Parse(); err != nil {
+	if err := e.Data.ParseWithTypes(types); err != nil {
 		return err
 	}
 	return nil
@@ -40,3 +45,8 @@ func (e *EventDescription) UpdateData() error {
 func (e *EventDescription) Validate(v interface{}) (bool, error) {
 	return e.Data.Validate(v)
 }
+
+// Check 验证数据，返回所有错误
+func (e *EventDescription) Check(v interface{}) *dataspec.ValidationResult {
+	return e.Data.Check(v)
+}
@@ -0,0 +1,67 @@
+package property
+
+import (
+	"github.com/AtomPod/thingmodel/thingmodel/dataspec"
+)
+
+// PropertyBuilder 属性描述构造器
+type PropertyBuilder struct {
+	desc PropertyDescription
+	data *dataspec.DataBuilder
+}
+
+// NewProperty 创建属性描述构造器，访问模式默认为wr
+func NewProperty(name string, data *dataspec.DataBuilder) *PropertyBuilder {
+	return &PropertyBuilder{
+		desc: PropertyDescription{Name: name, AccessMode: "wr"},
+		data: data,
+	}
+}
+
+// Name 属性名称
+func (b *PropertyBuilder) Name() string {
+	return b.desc.Name
+}
+
+// Describe 属性描述，作为解释说明
+func (b *PropertyBuilder) Describe(text string) *PropertyBuilder {
+	b.desc.Description = text
+	return b
+}
+
+// Required 属性必须存在
+func (b *PropertyBuilder) Required() *PropertyBuilder {
+	b.desc.Required = true
+	return b
+}
+
+// AccessMode 属性的访问支持，见PropertyDescription.AccessMode
+func (b *PropertyBuilder) AccessMode(mode string) *PropertyBuilder {
+	b.desc.AccessMode = mode
+	return b
+}
+
+// Description 返回未解析的属性描述，以及构造过程中的所有错误
+func (b *PropertyBuilder) Description() (*PropertyDescription, error) {
+	desc := b.desc
+	if b.data == nil {
+		return &desc, nil
+	}
+
+	data, err := b.data.Description()
+	desc.Data = data
+	return &desc, dataspec.PrefixErrors("data", err)
+}
+
+// Build 返回解析后的属性描述
+func (b *PropertyBuilder) Build() (*PropertyDescription, error) {
+	desc, err := b.Description()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := desc.UpdateData(); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
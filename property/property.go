@@ -19,7 +19,7 @@ type PropertyDescription struct {
 	// Required 是否必须存在
 	Required bool `json:"required"`
 
-	// AccessMode 属性的访问支持，其中w代表可写、r代表可读，该属性仅支持这两个字符
+	// AccessMode 属性的访问支持，其中w代表可写、r代表可读、n代表设备会主动上报(通知)，该属性仅支持这三个字符
 	AccessMode string `json:"access_mode"`
 
 	// Data 属性对应的数据描述
@@ -27,7 +27,7 @@ type PropertyDescription struct {
 }
 
 func (p *PropertyDescription) isAccessMode(c byte) bool {
-	return c == 'w' || c == 'r'
+	return c == 'w' || c == 'r' || c == 'n'
 }
 
 // Readable 是否可读
@@ -40,21 +40,42 @@ func (p *PropertyDescription) Writable() bool {
 	return strings.Contains(p.AccessMode, "w")
 }
 
+// Notifiable 设备是否会主动上报该属性
+func (p *PropertyDescription) Notifiable() bool {
+	return strings.Contains(p.AccessMode, "n")
+}
+
+// Reportable 设备是否可以上报该属性，可读或设备会主动上报(n)时可以上报
+func (p *PropertyDescription) Reportable() bool {
+	return p.Readable() || p.Notifiable()
+}
+
 func (p *PropertyDescription) UpdateData() error {
+	return p.UpdateDataWithTypes(nil)
+}
+
+// UpdateDataWithTypes 同UpdateData，数据描述中的ref从types中解析
+func (p *PropertyDescription) UpdateDataWithTypes(types dataspec.Types) error {
 	if len(p.Name) == 0 {
 		return fmt.Errorf("PropertyDescription: name could not be empty")
 	}
 
 	l := len(p.AccessMode)
-	if l > 2 || (l > 0 && !p.isAccessMode(p.AccessMode[0])) || (l > 1 && !p.isAccessMode(p.AccessMode[1])) {
+	if l > 3 {
 		return fmt.Errorf("PropertyDescription: access mode is invalid")
 	}
+	for i := 0; i < l; i++ {
+		c := p.AccessMode[i]
+		if !p.isAccessMode(c) || strings.IndexByte(p.AccessMode[:i], c) >= 0 {
+			return fmt.Errorf("PropertyDescription: access mode is invalid")
+		}
+	}
 
 	if p.Data == nil {
 		return fmt.Errorf("PropertyDescription: data field could not be empty")
 	}
 
-	if err := p.Data.Parse(); err != nil {
+	if err := p.Data.ParseWithTypes(types); err != nil {
 		return err
 	}
 	return nil
@@ -69,5 +90,11 @@ func (p *PropertyDescription) Parse(b []byte) error {
 
 // Validate 验证数据是否正确
 func (p *PropertyDescription) Validate(v interface{}) (bool, error) {
-	return p.Data.Validate(v)
+	r := p.Check(v)
+	return r.Valid(), r.Err()
+}
+
+// Check 验证数据，返回所有错误，错误路径以属性名称开头
+func (p *PropertyDescription) Check(v interface{}) *dataspec.ValidationResult {
+	return p.Data.CheckPath(p.Name, v)
 }
@@ -1,9 +1,18 @@
 package property_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/AtomPod/thingmodel/thingmodel/dataspec"
 	"github.com/AtomPod/thingmodel/thingmodel/property"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -459,7 +468,7 @@ func TestArrayValidate(t *testing.T) {
 		"data": {
 			"type": "array",
 			"specs": {
-				"length": 5,
+				"max_length": 5,
 				"data": {
 					"type": "number",
 					"specs": {
@@ -580,3 +589,1533 @@ func TestStructValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestEnumValidate(t *testing.T) {
+	dataStr := `{
+				"name": "fan_speed",
+				"description": "",
+				"data": {
+					"type": "enum",
+					"specs": {
+						"values": {
+							"0": "low",
+							"1": "mid",
+							"2": "high"
+						}
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{
+			0, true,
+		},
+		{
+			2, true,
+		},
+		{
+			1.0, true,
+		},
+		{
+			3, false,
+		},
+		{
+			1.5, false,
+		},
+		{
+			"1", false,
+		},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, ok, v.Ok)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	dataStr = `{
+				"name": "mode",
+				"description": "",
+				"data": {
+					"type": "enum",
+					"specs": {
+						"value_type": "string",
+						"values": {
+							"auto": "自动",
+							"manual": "手动"
+						}
+					}
+				}
+			}`
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate("auto")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = d.Validate("sleep")
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+
+	ok, err = d.Validate(0)
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+
+	err = d.Parse([]byte(`{"name": "mode", "data": {"type": "enum", "specs": {"values": {"a": "a"}}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestStructRequiredValidate(t *testing.T) {
+	dataStr := `{
+				"name": "hello",
+				"description": "",
+				"data": {
+					"type": "struct",
+					"specs": {
+						"additional_fields": "%s",
+						"fields": {
+							"name": {
+								"type": "string",
+								"required": true,
+								"specs": {
+									"length": 15
+								}
+							},
+							"age": {
+								"type": "integer",
+								"specs": {
+									"min": 0,
+									"max": 15
+								}
+							}
+						}
+					}
+				}
+			}`
+
+	type hello struct {
+		Name  string `json:"name"`
+		Age   int    `json:"age,omitempty"`
+		Extra string `json:"extra"`
+	}
+
+	validData := []struct {
+		Policy string
+		Value  interface{}
+		Ok     bool
+	}{
+		{"", map[string]interface{}{"name": "a"}, true},
+		{"", map[string]interface{}{"name": "a", "age": 1}, true},
+		{"", map[string]interface{}{"age": 1}, false},
+		{"", map[string]interface{}{"name": "a", "extra": 1}, false},
+		{"reject", hello{Name: "a"}, false},
+		{"ignore", hello{Name: "a"}, true},
+		{"ignore", map[string]interface{}{"age": 1, "extra": 1}, false},
+		{"allow", &hello{Name: "a", Age: 20}, false},
+		{"allow", &hello{Name: "a", Age: 2}, true},
+		{"other", nil, false},
+	}
+
+	for _, v := range validData {
+		d := property.PropertyDescription{}
+		err := d.Parse([]byte(fmt.Sprintf(dataStr, v.Policy)))
+		if v.Policy == "other" {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err)
+
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(fmt.Sprintf(dataStr, "")))
+	assert.Nil(t, err)
+
+	_, err = d.Validate(map[string]interface{}{"age": 1})
+	assert.ErrorIs(t, err, dataspec.ErrMissingField)
+
+	// nil指针与omitempty的空值视为缺失，与map中不存在该成员一致
+	type optional struct {
+		Name *string `json:"name"`
+		Age  *int    `json:"age,omitempty"`
+	}
+	name, age := "a", 3
+	ok, err := d.Validate(optional{Name: &name})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = d.Validate(optional{Name: &name, Age: &age})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	r := d.Check(optional{Age: &age})
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, "hello.name", r.Violations[0].Path)
+	assert.ErrorIs(t, r.Violations[0], dataspec.ErrMissingField)
+}
+
+func TestArrayLengthValidate(t *testing.T) {
+	dataStr := `{
+		"name": "temp",
+		"description": "temp",
+		"data": {
+			"type": "array",
+			"specs": {
+				"length": 3,
+				"data": {
+					"type": "integer",
+					"specs": {}
+				}
+			}
+		}
+	}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{[]int{1, 2, 3}, true},
+		{[]int{1, 2}, false},
+		{[]int{1, 2, 3, 4}, false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.ErrorIs(t, err, dataspec.ErrLengthMismatch)
+		}
+	}
+
+	// 只设置min_length时不限制最大长度
+	err = d.Parse([]byte(`{"name": "temp", "data": {"type": "array", "specs": {"min_length": 1, "data": {"type": "integer", "specs": {}}}}}`))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate(make([]int, 1000))
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	_, err = d.Validate([]int{})
+	assert.ErrorIs(t, err, dataspec.ErrLengthMismatch)
+
+	invalidSpecs := []string{
+		`{"data": {"type": "integer", "specs": {}}}`,
+		`{"length": 3, "max_length": 5, "data": {"type": "integer", "specs": {}}}`,
+		`{"min_length": 6, "max_length": 5, "data": {"type": "integer", "specs": {}}}`,
+		`{"max_length": 5, "unique_key": "id", "data": {"type": "integer", "specs": {}}}`,
+		`{"max_length": 5, "unique_key": "id", "data": {"type": "struct", "specs": {"name": {"type": "string", "specs": {}}}}}`,
+	}
+	for _, specs := range invalidSpecs {
+		d := property.PropertyDescription{}
+		err := d.Parse([]byte(`{"name": "temp", "data": {"type": "array", "specs": ` + specs + `}}`))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestArrayUniqueValidate(t *testing.T) {
+	dataStr := `{
+		"name": "timers",
+		"description": "",
+		"data": {
+			"type": "array",
+			"specs": {
+				"min_length": 1,
+				"max_length": 3,
+				"unique_key": "id",
+				"data": {
+					"type": "struct",
+					"specs": {
+						"id": {
+							"type": "integer",
+							"specs": {}
+						},
+						"time": {
+							"type": "string",
+							"specs": {}
+						}
+					}
+				}
+			}
+		}
+	}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{[]interface{}{}, false},
+		{[]interface{}{map[string]interface{}{"id": 1, "time": "08:00"}}, true},
+		{[]interface{}{
+			map[string]interface{}{"id": 1, "time": "08:00"},
+			map[string]interface{}{"id": 2, "time": "08:00"},
+		}, true},
+		{[]interface{}{
+			map[string]interface{}{"id": 1, "time": "08:00"},
+			map[string]interface{}{"id": 1.0, "time": "09:00"},
+		}, false},
+		{[]interface{}{
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"id": 2},
+			map[string]interface{}{"id": 3},
+			map[string]interface{}{"id": 4},
+		}, false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	r := d.Check([]interface{}{
+		map[string]interface{}{"id": 1},
+		map[string]interface{}{"id": 1},
+	})
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, "timers[1]", r.Violations[0].Path)
+	assert.ErrorIs(t, r.Violations[0], dataspec.ErrNotUnique)
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(`{"name": "tags", "data": {"type": "array", "specs": {"max_length": 5, "unique": true, "data": {"type": "string", "specs": {}}}}}`))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate([]string{"a", "b", "a"})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, dataspec.ErrNotUnique)
+}
+
+func TestStringConstraintValidate(t *testing.T) {
+	dataStr := `{
+				"name": "device_name",
+				"description": "",
+				"data": {
+					"type": "string",
+					"specs": {
+						"min_length": 2,
+						"length": 5,
+						"length_unit": "rune",
+						"pattern": "^[^0-9]"
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{"客厅空调", true},
+		{"客厅的空调器", false},
+		{"a", false},
+		{"1abc", false},
+		{"abcde", true},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	_, err = d.Validate("1")
+	assert.ErrorIs(t, err, dataspec.ErrLengthMismatch)
+	assert.ErrorIs(t, err, dataspec.ErrPatternMismatch)
+
+	formats := []struct {
+		Format string
+		Value  string
+		Ok     bool
+	}{
+		{"ipv4", "192.168.1.1", true},
+		{"ipv4", "192.168.1.256", false},
+		{"ipv4", "fe80::1", false},
+		{"ipv6", "fe80::1", true},
+		{"ipv6", "192.168.1.1", false},
+		{"mac", "00:1a:2b:3c:4d:5e", true},
+		{"mac", "00:1a:2b:3c:4d", false},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123e4567e89b12d3a456426614174000", false},
+		{"email", "user@example.com", true},
+		{"email", "User <user@example.com>", false},
+		{"uri", "mqtt://broker.example.com:1883", true},
+		{"uri", "/relative/path", false},
+		{"hostname", "device-01.example.com", true},
+		{"hostname", "-device.example.com", false},
+		{"date-time", "2024-01-02T15:04:05+08:00", true},
+		{"date-time", "2024-01-02 15:04:05", false},
+	}
+
+	for _, v := range formats {
+		d := property.PropertyDescription{}
+		err := d.Parse([]byte(fmt.Sprintf(`{"name": "f", "data": {"type": "string", "specs": {"format": "%s"}}}`, v.Format)))
+		assert.Nil(t, err)
+
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if !v.Ok {
+			assert.ErrorIs(t, err, dataspec.ErrFormatMismatch)
+		}
+	}
+
+	invalidSpecs := []string{
+		`{"format": "phone"}`,
+		`{"pattern": "(["}`,
+		`{"length_unit": "word"}`,
+		`{"min_length": 5, "length": 3}`,
+	}
+	for _, specs := range invalidSpecs {
+		d := property.PropertyDescription{}
+		err := d.Parse([]byte(`{"name": "s", "data": {"type": "string", "specs": ` + specs + `}}`))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestTimestampValidate(t *testing.T) {
+	dataStr := `{
+				"name": "last_maintenance",
+				"description": "",
+				"data": {
+					"type": "timestamp",
+					"specs": {
+						"format": "%s",
+						"min": "2020-01-01T00:00:00Z",
+						"max": "2030-01-01T00:00:00Z"
+					}
+				}
+			}`
+
+	validData := []struct {
+		Format string
+		Value  interface{}
+		Ok     bool
+	}{
+		{"rfc3339", "2024-01-02T15:04:05+08:00", true},
+		{"rfc3339", "2019-12-31T23:59:59Z", false},
+		{"rfc3339", "2024-01-02", false},
+		{"rfc3339", 1704178800, false},
+		{"unix", 1704178800, true},
+		{"unix", 1704178800.0, true},
+		{"unix", 1704178800000, false},
+		{"unix_ms", 1704178800000, true},
+		{"unix_ms", "1704178800000", false},
+		{"unix_ms", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), true},
+		{"rfc3339", time.Date(2031, 1, 2, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, v := range validData {
+		d := property.PropertyDescription{}
+		err := d.Parse([]byte(fmt.Sprintf(dataStr, v.Format)))
+		assert.Nil(t, err)
+
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(`{
+		"name": "schedule",
+		"data": {
+			"type": "struct",
+			"specs": {
+				"start": {"type": "timestamp", "specs": {}},
+				"repeat": {"type": "boolean", "specs": {}}
+			}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	type schedule struct {
+		Start  time.Time `json:"start"`
+		Repeat bool      `json:"repeat"`
+	}
+	ok, err := d.Validate(&schedule{Start: time.Now(), Repeat: true})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	err = d.Parse([]byte(`{"name": "t", "data": {"type": "timestamp", "specs": {"format": "unix_ns"}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestBytesValidate(t *testing.T) {
+	dataStr := `{
+				"name": "frame",
+				"description": "",
+				"data": {
+					"type": "bytes",
+					"specs": {
+						"min_length": 2,
+						"max_length": 4,
+						"encoding": "%s"
+					}
+				}
+			}`
+
+	validData := []struct {
+		Encoding string
+		Value    interface{}
+		Ok       bool
+	}{
+		{"base64", []byte{1, 2, 3}, true},
+		{"base64", []byte{1}, false},
+		{"base64", []byte{1, 2, 3, 4, 5}, false},
+		{"base64", "AQID", true},
+		{"base64", "+/8=", true},
+		{"base64", "+/8", true},
+		{"base64", "-_8=", false},
+		{"base64url", "-_8=", true},
+		{"base64url", "-_8", true},
+		{"base64", "AQIDBAU=", false},
+		{"base64", "not base64!", false},
+		{"base64", []int{1, 2, 3}, false},
+	}
+
+	for _, v := range validData {
+		d := property.PropertyDescription{}
+		err := d.Parse([]byte(fmt.Sprintf(dataStr, v.Encoding)))
+		assert.Nil(t, err)
+
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(fmt.Sprintf(dataStr, "hex")))
+	assert.NotNil(t, err)
+}
+
+func TestMapValidate(t *testing.T) {
+	dataStr := `{
+				"name": "zones",
+				"description": "",
+				"data": {
+					"type": "map",
+					"specs": {
+						"min_size": 1,
+						"max_size": 3,
+						"key": {
+							"type": "integer",
+							"specs": {
+								"min": 1,
+								"max": 8
+							}
+						},
+						"value": {
+							"type": "number",
+							"specs": {
+								"min": -40,
+								"max": 120
+							}
+						}
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{map[string]interface{}{"1": 20.5, "2": 21.0}, true},
+		{map[int]float64{1: 20.5, 8: -10}, true},
+		{map[string]interface{}{}, false},
+		{map[string]interface{}{"1": 1, "2": 2, "3": 3, "4": 4}, false},
+		{map[string]interface{}{"9": 20.5}, false},
+		{map[string]interface{}{"zone": 20.5}, false},
+		{map[string]interface{}{"1": 200}, false},
+		{map[string]interface{}{"1": "hot"}, false},
+		{[]float64{20.5}, false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	r := d.Check(map[string]interface{}{"1": 200, "9": 20})
+	assert.Len(t, r.Violations, 2)
+	assert.Equal(t, "zones.1", r.Violations[0].Path)
+	assert.ErrorIs(t, r.Violations[0], dataspec.ErrOutOfRange)
+	assert.Equal(t, "zones.9", r.Violations[1].Path)
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(`{
+		"name": "fingerprints",
+		"data": {
+			"type": "map",
+			"specs": {
+				"key": {"type": "string", "specs": {"format": "uuid"}},
+				"value": {"type": "string", "specs": {"length": 32}}
+			}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate(map[string]string{"123e4567-e89b-12d3-a456-426614174000": "finger"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = d.Validate(map[string]string{"user": "finger"})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, dataspec.ErrFormatMismatch)
+
+	err = d.Parse([]byte(`{"name": "m", "data": {"type": "map", "specs": {"key": {"type": "number", "specs": {}}, "value": {"type": "number", "specs": {}}}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestDecimalStepValidate(t *testing.T) {
+	dataStr := `{
+				"name": "temp",
+				"description": "",
+				"data": {
+					"type": "number",
+					"specs": {
+						"min": 50,
+						"max": 100,
+						"step": 0.01
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{50.07, true},
+		{99.99, true},
+		{float32(60.13), true},
+		{50.075, false},
+		{json.Number("70.01"), true},
+		{json.Number("70.0100000000000000001"), false},
+		{decimal.RequireFromString("80.55"), true},
+		{decimal.RequireFromString("100.01"), false},
+		{"70.01", false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+}
+
+func TestDecimalValidate(t *testing.T) {
+	dataStr := `{
+				"name": "balance",
+				"description": "",
+				"data": {
+					"type": "decimal",
+					"specs": {
+						"min": "0",
+						"max": "99999999.99",
+						"scale": 2
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{"12345678.91", true},
+		{"0.10", true},
+		{"0.100", true},
+		{"0.001", false},
+		{"100000000", false},
+		{"-1", false},
+		{json.Number("19.99"), true},
+		{decimal.RequireFromString("19.999"), false},
+		{12.5, true},
+		{100, true},
+		{"abc", false},
+		{true, false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	_, err = d.Validate("0.001")
+	assert.ErrorIs(t, err, dataspec.ErrScaleExceeded)
+
+	err = d.Parse([]byte(`{"name": "energy", "data": {"type": "decimal", "specs": {"min": 0, "step": "0.5"}}}`))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate("1.5")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = d.Validate("1.25")
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, dataspec.ErrStepMismatch)
+
+	err = d.Parse([]byte(`{"name": "energy", "data": {"type": "decimal", "specs": {"min": "2", "max": "1"}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestIntFormatValidate(t *testing.T) {
+	dataStr := `{
+				"name": "counter",
+				"description": "",
+				"data": {
+					"type": "integer",
+					"specs": {
+						"format": "uint64"
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{uint64(math.MaxUint64), true},
+		{uint64(math.MaxInt64) + 1, true},
+		{0, true},
+		{-1, false},
+		{json.Number("18446744073709551615"), true},
+		{json.Number("18446744073709551616"), false},
+		{json.Number("9007199254740993"), true},
+		{json.Number("1.5"), false},
+		{1.5, false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	dataStr = `{
+				"name": "level",
+				"description": "",
+				"data": {
+					"type": "integer",
+					"specs": {
+						"format": "int8",
+						"min": -200,
+						"max": 100
+					}
+				}
+			}`
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData = []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{-128, true},
+		{-129, false},
+		{-3.0, true},
+		{100, true},
+		{101, false},
+		{json.Number("-128"), true},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	_, err = d.Validate(-129)
+	var rangeErr *dataspec.RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, int64(-128), rangeErr.Min)
+	assert.Equal(t, int64(100), rangeErr.Max)
+
+	err = d.Parse([]byte(`{"name": "level", "data": {"type": "integer", "specs": {"format": "int128"}}}`))
+	assert.NotNil(t, err)
+
+	err = d.Parse([]byte(`{"name": "level", "data": {"type": "integer", "specs": {"format": "uint8", "max": -1}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestNumberBoundsValidate(t *testing.T) {
+	dataStr := `{
+				"name": "ratio",
+				"description": "",
+				"data": {
+					"type": "number",
+					"specs": {
+						"min": 0,
+						"max": 1,
+						"exclusive_min": true,
+						"multiple_of": 0.05
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{0.0, false},
+		{0.05, true},
+		{0.35, true},
+		{0.37, false},
+		{1.0, true},
+		{math.NaN(), false},
+		{math.Inf(1), false},
+		{math.Inf(-1), false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	_, err = d.Validate(0)
+	var rangeErr *dataspec.RangeError
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, "(0, 1]", rangeErr.Constraint())
+
+	_, err = d.Validate(math.NaN())
+	assert.ErrorIs(t, err, dataspec.ErrNotFinite)
+
+	dataStr = `{
+				"name": "temperature",
+				"description": "",
+				"data": {
+					"type": "number",
+					"specs": {
+						"max": 100,
+						"exclusive_max": true,
+						"step": 0.5,
+						"allow_nan": true,
+						"allow_inf": true
+					}
+				}
+			}`
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData = []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{-20.5, true},
+		{99.5, true},
+		{100.0, false},
+		{0.25, false},
+		{math.NaN(), true},
+		{math.Inf(1), false},
+		{math.Inf(-1), true},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	// 允许Inf时仍然检查取值范围
+	err = d.Parse([]byte(`{"name": "level", "data": {"type": "number", "specs": {"min": 0, "max": 100, "allow_inf": true}}}`))
+	assert.Nil(t, err)
+
+	_, err = d.Validate(math.Inf(-1))
+	assert.ErrorAs(t, err, &rangeErr)
+	assert.Equal(t, "[0, 100]", rangeErr.Constraint())
+
+	ok, _ := d.Validate(math.Inf(1))
+	assert.False(t, ok)
+
+	err = d.Parse([]byte(`{"name": "ratio", "data": {"type": "number", "specs": {"min": 1, "max": 1, "exclusive_max": true}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestIntBoundsValidate(t *testing.T) {
+	dataStr := `{
+				"name": "volume",
+				"description": "",
+				"data": {
+					"type": "integer",
+					"specs": {
+						"min": 0,
+						"max": 100,
+						"exclusive_min": true,
+						"exclusive_max": true,
+						"multiple_of": 5
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	validData := []struct {
+		Value interface{}
+		Ok    bool
+	}{
+		{0, false},
+		{5, true},
+		{95, true},
+		{97, false},
+		{100, false},
+	}
+
+	for _, v := range validData {
+		ok, err := d.Validate(v.Value)
+		assert.Equal(t, v.Ok, ok, v.Value)
+		if v.Ok {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+
+	dataStr = `{
+				"name": "offset",
+				"description": "",
+				"data": {
+					"type": "integer",
+					"specs": {
+						"max": 100,
+						"step": 10
+					}
+				}
+			}`
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate(-30)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	_, err = d.Validate(15)
+	assert.ErrorIs(t, err, dataspec.ErrStepMismatch)
+
+	// 显式设置为Int64最大值的max不会被当作未设置
+	err = d.Parse([]byte(`{"name": "counter", "data": {"type": "integer", "specs": {"format": "uint64", "max": 9223372036854775807}}}`))
+	assert.Nil(t, err)
+	ok, _ = d.Validate(uint64(math.MaxInt64))
+	assert.True(t, ok)
+	ok, _ = d.Validate(uint64(math.MaxInt64) + 1)
+	assert.False(t, ok)
+
+	b, err := json.Marshal(d.Data)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"max":9223372036854775807`)
+
+	err = d.Parse([]byte(`{"name": "counter", "data": {"type": "integer", "specs": {"format": "int64", "max": 9223372036854775807, "exclusive_max": true}}}`))
+	assert.Nil(t, err)
+	ok, _ = d.Validate(int64(math.MaxInt64))
+	assert.False(t, ok)
+	ok, _ = d.Validate(int64(math.MaxInt64 - 1))
+	assert.True(t, ok)
+
+	// 未设置max时取格式的最大值
+	err = d.Parse([]byte(`{"name": "counter", "data": {"type": "integer", "specs": {"format": "uint64"}}}`))
+	assert.Nil(t, err)
+	ok, _ = d.Validate(uint64(math.MaxUint64))
+	assert.True(t, ok)
+
+	integer, err := dataspec.Integer().Format("uint64").IntRange(0, math.MaxInt64).Build()
+	assert.Nil(t, err)
+	ok, _ = integer.Validate(uint64(math.MaxInt64) + 1)
+	assert.False(t, ok)
+}
+
+func TestCoerce(t *testing.T) {
+	dataStr := `{
+				"name": "settings",
+				"description": "",
+				"data": {
+					"type": "struct",
+					"specs": {
+						"additional_fields": "ignore",
+						"fields": {
+							"level": {"type": "integer", "specs": {"min": 0, "max": 100}},
+							"temp": {"type": "number", "specs": {"min": 0, "max": 100, "step": 0.1, "precision": 0.000001}},
+							"enabled": {"type": "boolean", "specs": {}},
+							"name": {"type": "string", "specs": {"length": 15}},
+							"zones": {"type": "array", "specs": {"max_length": 5, "data": {"type": "integer", "specs": {"min": 0, "max": 10}}}}
+						}
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	value := map[string]interface{}{
+		"level":   float64(25),
+		"temp":    0.1 + 0.2,
+		"enabled": true,
+		"name":    "fan",
+		"zones":   []int{1, 2},
+		"extra":   "dropped",
+	}
+
+	out, err := d.Data.Normalize(value)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"level":   int64(25),
+		"temp":    0.3,
+		"enabled": true,
+		"name":    "fan",
+		"zones":   []interface{}{int64(1), int64(2)},
+	}, out)
+
+	// allow策略下未声明的字段会被保留，reject策略下验证失败
+	specs := d.Data.Specs.(*dataspec.StructDataSpec)
+	specs.AdditionalFields = dataspec.AdditionalFieldsAllow
+	out, err = d.Data.Normalize(map[string]interface{}{"level": 25, "extra": "kept"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"level": int64(25), "extra": "kept"}, out)
+
+	specs.AdditionalFields = dataspec.AdditionalFieldsReject
+	_, err = d.Data.Normalize(map[string]interface{}{"level": 25, "extra": "kept"})
+	assert.ErrorIs(t, err, dataspec.ErrUnknownField)
+	specs.AdditionalFields = dataspec.AdditionalFieldsIgnore
+
+	value = map[string]interface{}{
+		"level":   "25",
+		"enabled": 1,
+		"name":    12,
+		"zones":   []interface{}{json.Number("3")},
+	}
+
+	_, err = d.Data.Normalize(value)
+	var result *dataspec.ValidationResult
+	assert.ErrorAs(t, err, &result)
+	assert.Len(t, result.Violations, 3)
+	assert.ErrorIs(t, err, dataspec.ErrTypeMismatch)
+
+	out, err = d.Data.Coerce(value, dataspec.CoerceLenient)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"level":   int64(25),
+		"enabled": true,
+		"name":    "12",
+		"zones":   []interface{}{int64(3)},
+	}, out)
+
+	out, err = d.Data.Coerce(map[string]interface{}{"level": "200"}, dataspec.CoerceLenient)
+	assert.NotNil(t, err)
+	assert.ErrorIs(t, err, dataspec.ErrOutOfRange)
+	assert.Equal(t, map[string]interface{}{"level": "200"}, out)
+
+	timeStr := `{
+				"name": "updated",
+				"description": "",
+				"data": {
+					"type": "timestamp",
+					"specs": {
+						"format": "unix"
+					}
+				}
+			}`
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(timeStr))
+	assert.Nil(t, err)
+
+	out, err = d.Data.Normalize(time.Unix(1700000000, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1700000000), out)
+
+	out, err = d.Data.Coerce("1700000000", dataspec.CoerceLenient)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1700000000), out)
+}
+
+func TestSanitize(t *testing.T) {
+	dataStr := `{
+				"name": "report",
+				"description": "",
+				"data": {
+					"type": "struct",
+					"specs": {
+						"fields": {
+							"level": {"type": "integer", "specs": {"min": 0, "max": 100, "step": 5}},
+							"temp": {"type": "number", "specs": {"min": -20, "max": 60, "step": 0.5}},
+							"name": {"type": "string", "specs": {"length": 4, "length_unit": "rune"}},
+							"zones": {"type": "array", "specs": {"max_length": 2, "data": {"type": "integer", "specs": {"min": 0, "max": 10}}}}
+						}
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	out, adjustments, err := d.Data.Sanitize(map[string]interface{}{
+		"level": 103,
+		"temp":  21.3,
+		"name":  "客厅空调机",
+		"zones": []interface{}{12.0, 3, 4},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"level": int64(100),
+		"temp":  21.5,
+		"name":  "客厅空调",
+		"zones": []interface{}{int64(10), int64(3)},
+	}, out)
+
+	codes := make([]string, 0, len(adjustments))
+	for _, a := range adjustments {
+		codes = append(codes, fmt.Sprintf("%s:%s", a.Path, a.Code))
+	}
+	assert.Equal(t, []string{
+		"level:out_of_range",
+		"name:length_mismatch",
+		"temp:step_mismatch",
+		"zones[0]:out_of_range",
+		"zones:length_mismatch",
+	}, codes)
+	assert.Equal(t, int64(103), adjustments[0].From)
+	assert.Equal(t, int64(100), adjustments[0].To)
+
+	out, adjustments, err = d.Data.Sanitize(map[string]interface{}{"level": "high"})
+	assert.ErrorIs(t, err, dataspec.ErrTypeMismatch)
+	assert.Nil(t, adjustments)
+	assert.Equal(t, map[string]interface{}{"level": "high"}, out)
+
+	dataStr = `{
+				"name": "price",
+				"description": "",
+				"data": {
+					"type": "decimal",
+					"specs": {
+						"min": "0",
+						"scale": 2
+					}
+				}
+			}`
+
+	d = property.PropertyDescription{}
+	err = d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	out, adjustments, err = d.Data.Sanitize("-1.5")
+	assert.Nil(t, err)
+	assert.True(t, decimal.Zero.Equal(out.(decimal.Decimal)))
+	assert.Len(t, adjustments, 1)
+
+	out, _, err = d.Data.Sanitize(json.Number("12.345"))
+	assert.Nil(t, err)
+	assert.Equal(t, "12.35", out.(decimal.Decimal).String())
+}
+
+// colorDataSpec 自定义的颜色数据类型，值为 #RRGGBB 格式的字符串
+type colorDataSpec struct {
+	Alpha bool `json:"alpha"`
+
+	pattern *regexp.Regexp
+}
+
+func (c *colorDataSpec) ParseSpec() error {
+	if c.Alpha {
+		c.pattern = regexp.MustCompile(`^#[0-9a-fA-F]{8}$`)
+	} else {
+		c.pattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	}
+	return nil
+}
+
+func (c *colorDataSpec) Validate(v interface{}) (bool, error) {
+	s, ok := v.(string)
+	if !ok || !c.pattern.MatchString(s) {
+		return false, errors.New("value is not a color")
+	}
+	return true, nil
+}
+
+func TestCustomType(t *testing.T) {
+	if _, ok := dataspec.LookupType("color"); !ok {
+		err := dataspec.RegisterType("color", func() dataspec.DataSpec { return &colorDataSpec{} })
+		assert.Nil(t, err)
+	}
+
+	err := dataspec.RegisterType("color", func() dataspec.DataSpec { return &colorDataSpec{} })
+	assert.NotNil(t, err)
+
+	err = dataspec.RegisterType(dataspec.StringType, func() dataspec.DataSpec { return &colorDataSpec{} })
+	assert.NotNil(t, err)
+	assert.Contains(t, dataspec.RegisteredTypes(), dataspec.DataType("color"))
+
+	dataStr := `{
+				"name": "light",
+				"description": "",
+				"data": {
+					"type": "struct",
+					"specs": {
+						"color": {"type": "color", "specs": {"alpha": true}},
+						"palette": {"type": "array", "specs": {"max_length": 3, "data": {"type": "color"}}}
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err = d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate(map[string]interface{}{"color": "#ff000080", "palette": []interface{}{"#00ff00"}})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	r := d.Check(map[string]interface{}{"color": "#ff0000", "palette": []interface{}{"#00ff00", "blue"}})
+	assert.False(t, r.Valid())
+
+	paths := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		paths = append(paths, v.Path)
+	}
+	assert.ElementsMatch(t, []string{"light.color", "light.palette[1]"}, paths)
+
+	err = d.Parse([]byte(`{"name": "light", "data": {"type": "colour"}}`))
+	assert.NotNil(t, err)
+}
+
+// luhn 使用Luhn算法校验数字字符串
+func luhn(v interface{}) error {
+	s, _ := v.(string)
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if d < 0 || d > 9 {
+			return errors.New("must be digits")
+		}
+
+		if (len(s)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	if sum%10 != 0 {
+		return errors.New("luhn checksum mismatch")
+	}
+	return nil
+}
+
+// checksum 校验结构体中checksum成员为payload各字节之和
+func checksum(v interface{}) error {
+	m := v.(map[string]interface{})
+	payload, _ := m["payload"].(string)
+
+	sum := 0
+	for i := 0; i < len(payload); i++ {
+		sum += int(payload[i])
+	}
+
+	if c, _ := m["checksum"].(int); c != sum%256 {
+		r := &dataspec.ValidationResult{}
+		r.Add(&dataspec.Violation{Path: "checksum", Value: m["checksum"], Code: dataspec.CodeConstraintFailed, Message: "checksum mismatch"})
+		return r
+	}
+	return nil
+}
+
+func TestConstraints(t *testing.T) {
+	for name, fn := range map[string]dataspec.ConstraintFunc{"luhn": luhn, "checksum": checksum} {
+		if _, ok := dataspec.LookupConstraint(name); !ok {
+			assert.Nil(t, dataspec.RegisterConstraint(name, fn))
+		}
+	}
+	assert.NotNil(t, dataspec.RegisterConstraint("luhn", luhn))
+
+	dataStr := `{
+				"name": "frame",
+				"description": "",
+				"data": {
+					"type": "struct",
+					"constraints": ["checksum"],
+					"specs": {
+						"serial": {"type": "string", "constraints": ["luhn"], "specs": {"length": 16}},
+						"payload": {"type": "string", "specs": {}},
+						"checksum": {"type": "integer", "specs": {"min": 0, "max": 255}}
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	ok, err := d.Validate(map[string]interface{}{"serial": "79927398713", "payload": "ab", "checksum": 195})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	r := d.Check(map[string]interface{}{"serial": "79927398710", "payload": "ab", "checksum": 195})
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, "frame.serial", r.Violations[0].Path)
+	assert.Equal(t, dataspec.CodeConstraintFailed, r.Violations[0].Code)
+	assert.Equal(t, "luhn", r.Violations[0].Constraint)
+	assert.ErrorIs(t, r, dataspec.ErrConstraintFailed)
+
+	r = d.Check(map[string]interface{}{"serial": "79927398713", "payload": "ab", "checksum": 7})
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, "frame.checksum", r.Violations[0].Path)
+
+	// 数据规格验证失败时不执行自定义约束
+	r = d.Check(map[string]interface{}{"serial": 12, "payload": "ab", "checksum": 195})
+	assert.Len(t, r.Violations, 1)
+	assert.Equal(t, dataspec.CodeTypeMismatch, r.Violations[0].Code)
+
+	err = d.Parse([]byte(`{"name": "serial", "data": {"type": "string", "constraints": ["unknown"], "specs": {}}}`))
+	assert.NotNil(t, err)
+
+	err = d.Parse([]byte(`{"name": "serial", "data": {"type": "string", "constraints": ["luhn"], "default": "1234", "specs": {}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestStructFieldOrder(t *testing.T) {
+	dataStr := `{
+				"name": "frame",
+				"description": "",
+				"data": {
+					"type": "struct",
+					"specs": {
+						"fields": {
+							"version": {"type": "integer", "specs": {"min": 0, "max": 255}},
+							"length": {"type": "integer", "specs": {"min": 0, "max": 65535}},
+							"command": {"type": "string", "specs": {}},
+							"checksum": {"type": "integer", "specs": {"min": 0, "max": 255}}
+						}
+					}
+				}
+			}`
+
+	d := property.PropertyDescription{}
+	err := d.Parse([]byte(dataStr))
+	assert.Nil(t, err)
+
+	specs := d.Data.Specs.(*dataspec.StructDataSpec)
+	assert.Equal(t, []string{"version", "length", "command", "checksum"}, specs.FieldNames())
+	assert.Equal(t, dataspec.IntegerType, specs.Fields["length"].Type)
+
+	b, err := json.Marshal(specs)
+	assert.Nil(t, err)
+
+	s := string(b)
+	assert.Less(t, strings.Index(s, `"version"`), strings.Index(s, `"length"`))
+	assert.Less(t, strings.Index(s, `"length"`), strings.Index(s, `"command"`))
+	assert.Less(t, strings.Index(s, `"command"`), strings.Index(s, `"checksum"`))
+
+	parsed := &dataspec.StructDataSpec{}
+	assert.Nil(t, json.Unmarshal(b, parsed))
+	assert.Equal(t, specs.FieldNames(), parsed.FieldNames())
+
+	// 兼容的成员列表形式同样保持顺序
+	legacy := &dataspec.StructDataSpec{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"z": {"type": "string"}, "a": {"type": "string"}, "m": {"type": "string"}}`), legacy))
+	assert.Equal(t, []string{"z", "a", "m"}, legacy.FieldNames())
+
+	// 旧版本的成员map可以直接转换
+	fromMap := dataspec.NewStructDataSpec(map[string]*dataspec.DataDescription{
+		"b": {Type: dataspec.StringType, Specs: &dataspec.StringDataSpec{}},
+		"a": {Type: dataspec.IntegerType, Specs: &dataspec.IntegerDataSpec{Min: 0, Max: 10}},
+	})
+	assert.Equal(t, []string{"a", "b"}, fromMap.FieldNames())
+	ok, err := (&dataspec.DataDescription{Type: dataspec.StructType, Specs: fromMap}).Validate(map[string]interface{}{"a": 1, "b": "x"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	// 在Go中添加的成员排在最后
+	legacy.Fields["b"] = &dataspec.DataDescription{Type: dataspec.StringType}
+	delete(legacy.Fields, "a")
+	assert.Equal(t, []string{"z", "m", "b"}, legacy.FieldNames())
+}
+
+func TestDataBuilder(t *testing.T) {
+	p, err := property.NewProperty("schedule", dataspec.Struct().
+		Field("name", dataspec.String().Length(1, 4).RuneLength().Required()).
+		Field("start", dataspec.Timestamp(dataspec.TimestampRFC3339).Required()).
+		Field("end", dataspec.Timestamp(dataspec.TimestampRFC3339).Required()).
+		Field("brightness", dataspec.Integer().IntRange(0, 100).Step(10).Unit("%").Default(50)).
+		Field("tags", dataspec.Array(dataspec.String()).Length(0, 3).Unique("")).
+		Field("mode", dataspec.StringEnum(map[string]string{"auto": "自动", "manual": "手动"})).
+		Assert("start < end")).
+		Describe("定时计划").
+		AccessMode("rw").
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "定时计划", p.Description)
+	assert.Equal(t, []string{"name", "start", "end", "brightness", "tags", "mode"}, p.Data.Specs.(*dataspec.StructDataSpec).FieldNames())
+
+	ok, err := p.Validate(map[string]interface{}{
+		"name":  "客厅灯光",
+		"start": "2024-01-01T08:00:00Z",
+		"end":   "2024-01-01T20:00:00Z",
+		"tags":  []string{"a", "b"},
+		"mode":  "auto",
+	})
+	assert.True(t, ok, err)
+
+	r := p.Check(map[string]interface{}{
+		"name":       "客厅灯光!",
+		"start":      "2024-01-01T20:00:00Z",
+		"end":        "2024-01-01T08:00:00Z",
+		"brightness": 55,
+		"tags":       []string{"a", "a"},
+	})
+	paths := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		paths = append(paths, v.Path)
+	}
+	assert.ElementsMatch(t, []string{"schedule.name", "schedule.brightness", "schedule.tags[1]"}, paths)
+
+	// 构造错误一起返回，并带有数据路径
+	_, err = dataspec.Struct().
+		Field("a", dataspec.Integer().Pattern("^a$")).
+		Field("a", dataspec.String()).
+		Field("b", dataspec.Array(dataspec.Number().Length(0, 1)).Length(0, 2)).
+		Field("c", dataspec.Map(nil)).
+		Field("d", dataspec.Decimal().DecimalRange("x", "1")).
+		Field("e", dataspec.Integer().Step(0.5)).
+		Build()
+	assert.NotNil(t, err)
+	for _, msg := range []string{
+		"a: DataBuilder: Pattern is not supported by type [integer]",
+		"DataBuilder: field [a] is duplicated",
+		"b[]: DataBuilder: Length is not supported by type [number]",
+		"c.<value>: DataBuilder: data could not be nil",
+		"d: DataBuilder: DecimalRange [x] is not a decimal",
+		"e: DataBuilder: Step [0.5] must be an integer",
+	} {
+		assert.ErrorContains(t, err, msg)
+	}
+
+	// 引用命名类型时不能修改规格
+	_, err = dataspec.Struct().
+		Field("a", dataspec.Ref("t").IntRange(0, 5).Nullable()).
+		Field("b", dataspec.Ref("t").Field("c", dataspec.String())).
+		Field("c", dataspec.Ref("t").Configure(func(specs dataspec.DataSpec) {})).
+		Build()
+	assert.NotNil(t, err)
+	for _, msg := range []string{
+		"a: DataBuilder: IntRange is not supported by ref [t]",
+		"b: DataBuilder: Field is not supported by ref [t]",
+		"c: DataBuilder: Configure is not supported by ref [t]",
+	} {
+		assert.ErrorContains(t, err, msg)
+	}
+
+	_, err = dataspec.NewDataBuilder("unknown").Build()
+	assert.ErrorContains(t, err, "type [unknown] is not supported")
+
+	// 构造成功但规格非法时返回解析错误
+	_, err = dataspec.Integer().IntRange(10, 1).Build()
+	assert.NotNil(t, err)
+
+	_, err = property.NewProperty("a", dataspec.Boolean("开", "关")).AccessMode("rr").Build()
+	assert.ErrorContains(t, err, "access mode is invalid")
+}